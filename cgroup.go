@@ -0,0 +1,125 @@
+package procfilter
+
+/* Control groups.
+The cgroup of a process is read from /proc/[pid]/cgroup. With cgroup v2 (unified hierarchy) there is a single "0::/path" line. With cgroup v1 there is one line per hierarchy, the systemd one (name=systemd) is used as it follows the services structure, or else the cpu controller one. A root ("/") path is only used when no hierarchy gives a better one (eg: v1/v2 hybrid mode without systemd where only the v1 hierarchies are used).
+On systemd hosts the cgroup path follows the units structure and gives the slice and unit (service or scope) of the process.
+*/
+
+import (
+	"bytes"
+	"strings"
+)
+
+// cgroupRank ranks a line of /proc/[pid]/cgroup using its hierarchy ID and controller list. The cgroup of the process is the path of the highest ranked line.
+func cgroupRank(hierarchy, controllers []byte) int {
+	if len(controllers) == 0 && string(hierarchy) == "0" { // v2 (also present in the v1/v2 hybrid mode)
+		return 4
+	}
+	if string(controllers) == "name=systemd" {
+		return 3
+	}
+	for _, c := range bytes.Split(controllers, []byte{','}) {
+		if string(c) == "cpu" {
+			return 2
+		}
+	}
+	return 1
+}
+
+// updateFromCgroup reads the cgroup of the process. It is read once (and again after an exec), processes are usually put in their cgroup around exec and seldom move later.
+func (ps *procStat) updateFromCgroup() {
+	if ps.cgroupRead || ps.status == DEAD {
+		return
+	}
+	ps.cgroupRead = true
+	err := fastReadOpen(procFileName(ps.pid, "cgroup"))
+	if err != nil {
+		return // eg: kernel without cgroup support
+	}
+	best := -1
+	for {
+		l := fastReadLine()
+		if l == nil {
+			break
+		}
+		// eg: 0::/system.slice/nginx.service (v2)
+		// eg: 1:name=systemd:/system.slice/nginx.service (v1)
+		// eg: 4:cpu,cpuacct:/system.slice/nginx.service (v1)
+		i := findNextIndex(l, 0, ':')
+		j := findNextIndex(l, i+1, ':')
+		if j >= len(l) {
+			continue
+		}
+		rank := 0
+		if j+2 < len(l) { // Not the root cgroup.
+			rank = cgroupRank(l[:i], l[i+1:j])
+		}
+		if rank > best {
+			best = rank
+			ps.cgroup = string(l[j+1:])
+		}
+	}
+	ps.unit, ps.slice = systemdUnit(ps.cgroup)
+	ps.containerID, ps.containerRuntime = containerFromCgroup(ps.cgroup)
+	ps.podUID, ps.podQoS = k8sPodFromCgroup(ps.cgroup)
+}
+
+// systemdUnit returns the systemd unit and slice of a cgroup path. The unit is the first non slice component (like systemd does, so processes of a user manager belong to its user@UID.service unit), the slice is the innermost slice containing it.
+// eg: /system.slice/nginx.service => nginx.service, system.slice
+// eg: /user.slice/user-1000.slice/session-3.scope => session-3.scope, user-1000.slice
+// eg: /init.scope => init.scope, -.slice (the root slice)
+func systemdUnit(cgroup string) (unit, slice string) {
+	for _, c := range strings.Split(cgroup, "/") {
+		if c == "" {
+			continue
+		}
+		if strings.HasSuffix(c, ".slice") {
+			slice = c
+			continue
+		}
+		if strings.HasSuffix(c, ".service") || strings.HasSuffix(c, ".scope") || strings.HasSuffix(c, ".socket") || strings.HasSuffix(c, ".mount") || strings.HasSuffix(c, ".swap") {
+			unit = c
+			if slice == "" {
+				slice = "-.slice"
+			}
+		}
+		break // Not a systemd managed cgroup or unit found.
+	}
+	if unit == "" {
+		return "", "" // eg: /docker/0123456789ab (not managed by systemd)
+	}
+	return unit, slice
+}
+
+// Cgroup is the path of the cgroup of the process (eg: /system.slice/nginx.service).
+func (ps *procStat) Cgroup() (string, error) {
+	ps.updateFromCgroup()
+	return ps.cgroup, nil
+}
+
+// Unit is the systemd unit (service or scope) of the process (eg: nginx.service, session-3.scope).
+func (ps *procStat) Unit() (string, error) {
+	ps.updateFromCgroup()
+	return ps.unit, nil
+}
+
+// Slice is the systemd slice of the process (eg: system.slice, user-1000.slice).
+func (ps *procStat) Slice() (string, error) {
+	ps.updateFromCgroup()
+	return ps.slice, nil
+}
+
+// Cgroup is the cgroup shared by all the pack elements ("" if they differ).
+func (p *packStat) Cgroup() (string, error) {
+	return p.common((*procStat).Cgroup), nil
+}
+
+// Unit is the systemd unit shared by all the pack elements ("" if they differ).
+func (p *packStat) Unit() (string, error) {
+	return p.common((*procStat).Unit), nil
+}
+
+// Slice is the systemd slice shared by all the pack elements ("" if they differ).
+func (p *packStat) Slice() (string, error) {
+	return p.common((*procStat).Slice), nil
+}
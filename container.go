@@ -0,0 +1,276 @@
+package procfilter
+
+/* Containers.
+The container ID is found in the cgroup path of a process. Its name and image are read from the metadata the runtimes keep on disk (no runtime daemon API call):
+- docker: /var/lib/docker/containers/[id]/config.v2.json
+- podman and cri-o (containers/storage): /var/lib/containers/storage/*-containers/containers.json
+- containerd (CRI): the OCI bundle /run/containerd/io.containerd.runtime.v2.task/[namespace]/[id]/config.json
+*/
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+// Root directories of the runtimes metadata.
+var dockerRoot = "/var/lib/docker"
+var storageRoot = "/var/lib/containers/storage"
+var containerdStateRoot = "/run/containerd/io.containerd.runtime.v2.task"
+
+// containerFromCgroup returns the container ID and runtime found in a cgroup path (the innermost matching component is used). The runtime is "" when it cannot be told from the path.
+// eg: /system.slice/docker-0123...cdef.scope => 0123...cdef, docker
+// eg: /kubepods/burstable/pod1234-5678/0123...cdef => 0123...cdef, ""
+func containerFromCgroup(cgroup string) (id, runtime string) {
+	cs := strings.Split(cgroup, "/")
+	for i := len(cs) - 1; i > 0; i-- {
+		c := strings.TrimSuffix(cs[i], ".scope")
+		runtime = ""
+		switch {
+		case strings.HasPrefix(c, "lxc.payload."):
+			return c[len("lxc.payload."):], "lxc"
+		case cs[i-1] == "lxc":
+			return c, "lxc"
+		case strings.HasPrefix(c, "crio-conmon-") || strings.HasPrefix(c, "libpod-conmon-"):
+			continue // The container monitor, not the container itself.
+		case strings.HasPrefix(c, "docker-"):
+			runtime = "docker"
+			c = c[len("docker-"):]
+		case strings.HasPrefix(c, "cri-containerd-"):
+			runtime = "containerd"
+			c = c[len("cri-containerd-"):]
+		case strings.HasPrefix(c, "crio-"):
+			runtime = "cri-o"
+			c = c[len("crio-"):]
+		case strings.HasPrefix(c, "libpod-"):
+			runtime = "podman"
+			c = c[len("libpod-"):]
+		case cs[i-1] == "docker":
+			runtime = "docker"
+		}
+		if isContainerID(c) {
+			return c, runtime
+		}
+	}
+	return "", ""
+}
+
+// isContainerID checks for the 64 hexadecimal digits IDs used by the docker, containerd, cri-o and podman runtimes.
+func isContainerID(s string) bool {
+	if len(s) != 64 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// Metadata of a container.
+type containerInfo struct {
+	ts       tStamp // Last sample this container was seen.
+	runtime  string
+	name     string
+	image    string
+	resolved bool // Metadata found, otherwise it is searched again at the next sample (eg: container being created).
+}
+
+// Known containers (indexed by ID). Containers not seen during the last sample are forgotten.
+var containerInfos = map[string]*containerInfo{}
+var containerInfosTs tStamp
+
+// getContainerInfo returns the metadata of a container.
+func getContainerInfo(id, runtime string) *containerInfo {
+	if containerInfosTs != stamp {
+		for k, ci := range containerInfos {
+			if ci.ts != containerInfosTs {
+				delete(containerInfos, k)
+			}
+		}
+		containerInfosTs = stamp
+	}
+	ci, known := containerInfos[id]
+	if !known {
+		ci = &containerInfo{runtime: runtime}
+		containerInfos[id] = ci
+	} else if ci.resolved || ci.ts == stamp {
+		ci.ts = stamp
+		return ci
+	}
+	ci.ts = stamp
+	switch ci.runtime {
+	case "docker":
+		ci.resolved = ci.readDocker(id)
+	case "podman", "cri-o":
+		ci.resolved = ci.readStorage(id)
+	case "containerd":
+		ci.resolved = ci.readContainerd(id)
+	case "lxc":
+		ci.name = id // lxc containers are identified by their name.
+		ci.resolved = true
+	default: // Unknown runtime (eg: kubernetes with the cgroupfs driver), try them all.
+		if ci.readDocker(id) {
+			ci.runtime = "docker"
+		} else if ci.readContainerd(id) {
+			ci.runtime = "containerd"
+		} else if ci.readStorage(id) {
+			ci.runtime = "cri-o"
+		}
+		ci.resolved = ci.runtime != ""
+	}
+	return ci
+}
+
+// readDocker reads the metadata of a docker container.
+func (ci *containerInfo) readDocker(id string) bool {
+	b, err := ioutil.ReadFile(filepath.Join(dockerRoot, "containers", id, "config.v2.json"))
+	if err != nil {
+		return false
+	}
+	var cfg struct {
+		Name   string
+		Config struct {
+			Image string
+		}
+	}
+	if json.Unmarshal(b, &cfg) != nil {
+		return false
+	}
+	ci.name = strings.TrimPrefix(cfg.Name, "/")
+	ci.image = cfg.Config.Image
+	return true
+}
+
+// A container as listed in the containers.json file of containers/storage.
+type storageContainer struct {
+	ID       string   `json:"id"`
+	Names    []string `json:"names"`
+	Metadata string   `json:"metadata"` // JSON string set by podman or cri-o (eg: {"image-name":"docker.io/library/nginx:latest","name":"web"}).
+}
+
+// Containers of containers/storage (indexed by ID). Read at most once per sample.
+var storageContainers map[string]*storageContainer
+var storageContainersTs tStamp
+
+// readStorage reads the metadata of a podman or cri-o container.
+func (ci *containerInfo) readStorage(id string) bool {
+	if storageContainers == nil || storageContainersTs != stamp {
+		storageContainersTs = stamp
+		storageContainers = map[string]*storageContainer{}
+		fns, _ := filepath.Glob(filepath.Join(storageRoot, "*-containers", "containers.json")) // eg: overlay-containers
+		for _, fn := range fns {
+			b, err := ioutil.ReadFile(fn)
+			if err != nil {
+				continue
+			}
+			var scs []*storageContainer
+			if json.Unmarshal(b, &scs) != nil {
+				continue
+			}
+			for _, sc := range scs {
+				storageContainers[sc.ID] = sc
+			}
+		}
+	}
+	sc, known := storageContainers[id]
+	if !known {
+		return false
+	}
+	var md struct {
+		Name      string `json:"name"`
+		ImageName string `json:"image-name"`
+	}
+	json.Unmarshal([]byte(sc.Metadata), &md)
+	ci.name = md.Name
+	if len(sc.Names) > 0 {
+		ci.name = sc.Names[0]
+	}
+	ci.image = md.ImageName
+	return true
+}
+
+// readContainerd reads the metadata of a containerd container from the annotations of its OCI bundle. Only containers created by the kubernetes CRI plugin have a name and an image, the ID is used as the name of the others.
+func (ci *containerInfo) readContainerd(id string) bool {
+	fns, _ := filepath.Glob(filepath.Join(containerdStateRoot, "*", id, "config.json")) // The first level is the containerd namespace (eg: k8s.io, moby, default)
+	if len(fns) == 0 {
+		return false
+	}
+	b, err := ioutil.ReadFile(fns[0])
+	if err != nil {
+		return false
+	}
+	var spec struct {
+		Annotations map[string]string `json:"annotations"`
+	}
+	if json.Unmarshal(b, &spec) != nil {
+		return false
+	}
+	ci.name = spec.Annotations["io.kubernetes.cri.container-name"]
+	if ci.name == "" {
+		ci.name = id
+	}
+	ci.image = spec.Annotations["io.kubernetes.cri.image-name"]
+	return true
+}
+
+// ContainerID is the ID of the container of the process ("" if the process is not in a container).
+func (ps *procStat) ContainerID() (string, error) {
+	ps.updateFromCgroup()
+	return ps.containerID, nil
+}
+
+// container returns the metadata of the container of the process (nil if the process is not in a container).
+func (ps *procStat) container() *containerInfo {
+	ps.updateFromCgroup()
+	if ps.containerID == "" {
+		return nil
+	}
+	return getContainerInfo(ps.containerID, ps.containerRuntime)
+}
+
+// ContainerName is the name of the container of the process.
+func (ps *procStat) ContainerName() (string, error) {
+	ci := ps.container()
+	if ci == nil {
+		return "", nil
+	}
+	return ci.name, nil
+}
+
+// ContainerImage is the image of the container of the process.
+func (ps *procStat) ContainerImage() (string, error) {
+	ci := ps.container()
+	if ci == nil {
+		return "", nil
+	}
+	return ci.image, nil
+}
+
+// ContainerRuntime is the runtime (docker, containerd, cri-o, podman or lxc) of the container of the process.
+func (ps *procStat) ContainerRuntime() (string, error) {
+	ci := ps.container()
+	if ci == nil {
+		return "", nil
+	}
+	return ci.runtime, nil
+}
+
+func (p *packStat) ContainerID() (string, error) {
+	return p.common((*procStat).ContainerID), nil
+}
+
+func (p *packStat) ContainerName() (string, error) {
+	return p.common((*procStat).ContainerName), nil
+}
+
+func (p *packStat) ContainerImage() (string, error) {
+	return p.common((*procStat).ContainerImage), nil
+}
+
+func (p *packStat) ContainerRuntime() (string, error) {
+	return p.common((*procStat).ContainerRuntime), nil
+}
@@ -9,6 +9,7 @@ import (
 )
 
 // Reserved variable names (accessible by tag() of field()) that are available builtins and that should not be overriden by a user declared dynamic variable.
+// Other builtins (added later) are hidden by a user variable of the same name so that older scripts keep working.
 var reservedVarNames = map[string]interface{}{
 	"cpu":        nil,
 	"process_nb": nil,
@@ -26,6 +27,13 @@ var reservedVarNames = map[string]interface{}{
 	"cmd_line":   nil,
 	"exe":        nil,
 	"path":       nil,
+
+	// Read through Var() by the pod filter.
+	"k8s_namespace": nil,
+	"k8s_pod":       nil,
+	"k8s_pod_uid":   nil,
+	"k8s_qos":       nil,
+	"k8s_container": nil,
 }
 
 /* A filter will select a set of processes.
@@ -61,10 +69,40 @@ func name2FuncFilter(funcName string) filter {
 		f = new(exeFilter)
 	case "path":
 		f = new(pathFilter)
+	case "cwd":
+		f = new(cwdFilter)
+	case "cgroup":
+		f = new(cgroupFilter)
+	case "unit":
+		f = new(unitFilter)
+	case "slice":
+		f = new(sliceFilter)
+	case "container":
+		f = new(containerFilter)
+	case "pod":
+		f = new(podFilter)
+	case "namespace":
+		f = new(namespaceFilter)
 	case "cmdline":
 		f = new(cmdlineFilter)
 	case "pid":
 		f = new(pidFilter)
+	case "state":
+		f = new(stateFilter)
+	case "realtime":
+		f = new(realtimeFilter)
+	case "nice":
+		f = new(niceFilter)
+	case "threads":
+		f = new(threadsFilter)
+	case "listen":
+		f = new(listenFilter)
+	case "holds_deleted":
+		f = new(holdsDeletedFilter)
+	case "younger":
+		f = &ageFilter{younger: true}
+	case "older":
+		f = &ageFilter{younger: false}
 	case "or", "union":
 		f = new(orFilter)
 	case "and", "intersection":
@@ -386,7 +424,18 @@ func (f *topFilter) Apply() error {
 			}
 		}
 	default:
-		return fmt.Errorf("unknown sort criteria %q", f.crit)
+		if !isKindName(f.crit) {
+			return fmt.Errorf("unknown sort criteria %q", f.crit)
+		}
+		for _, s := range iStats.pid2Stat {
+			v, err := kindFloat(s, f.crit)
+			if err != nil {
+				continue
+			}
+			if v > 0 {
+				stats = append(stats, s)
+			}
+		}
 	}
 	// sort it according to criteria
 	switch f.crit {
@@ -409,7 +458,10 @@ func (f *topFilter) Apply() error {
 	case "iobps":
 		sort.Sort(byIObps(stats))
 	default:
-		return fmt.Errorf("unknownsort criteria %q", f.crit)
+		if !isKindName(f.crit) {
+			return fmt.Errorf("unknownsort criteria %q", f.crit)
+		}
+		sort.Sort(byKind{f.crit, stats})
 	}
 	// build this filter procstat map (a subset of the one in input filter)
 	l := min(int(f.topNb), len(stats))
@@ -541,7 +593,16 @@ func (f *exceedFilter) Apply() error {
 				m[pid] = s
 			}
 		default:
-			return fmt.Errorf("unknown sort criteria %q", f.crit)
+			if !isKindName(f.crit) {
+				return fmt.Errorf("unknown sort criteria %q", f.crit)
+			}
+			v, err := kindFloat(s, f.crit)
+			if err != nil {
+				continue
+			}
+			if v > float64(f.iv) {
+				m[pid] = s
+			}
 		}
 	}
 	// Pack all other procStat in one stat.
@@ -572,6 +633,11 @@ func (f *exceedFilter) Parse(p *Parser) error {
 		if err != nil {
 			return p.syntaxError(fmt.Sprintf("exceed with '%s' criteri requires an integer as threshold", f.crit))
 		}
+	case "age":
+		err := p.parseArgDuration(&f.iv)
+		if err != nil {
+			return p.syntaxError(fmt.Sprintf("exceed with '%s' criteri requires a duration as threshold", f.crit))
+		}
 	case "cpu":
 		var v int64
 		err := p.parseArgInt(&v)
@@ -580,7 +646,13 @@ func (f *exceedFilter) Parse(p *Parser) error {
 		}
 		f.fv = float64(v)
 	default:
-		return fmt.Errorf("unknown exceed criteria %q", f.crit)
+		if !isKindName(f.crit) {
+			return fmt.Errorf("unknown exceed criteria %q", f.crit)
+		}
+		err := p.parseArgInt(&f.iv)
+		if err != nil {
+			return p.syntaxError(fmt.Sprintf("exceed with '%s' criteri requires an integer as threshold", f.crit))
+		}
 	}
 
 	err = p.parseArgLastFilter(&f.input)
@@ -877,17 +949,14 @@ func (f *childrenFilter) Stats() *stats {
 	return &f.stats
 }
 
-/* Filters related to the command line (exe, cmdline)
- */
-
-// Select matching command name (basename).
-type cmdFilter struct {
+// Select matching process state (eg: 'D' for processes blocked in uninterruptible sleep).
+type stateFilter struct {
 	stats
 	pat    *stregexp
 	inputs []filter
 }
 
-func (f *cmdFilter) Apply() error {
+func (f *stateFilter) Apply() error {
 	if !f.stats.reset() {
 		return nil
 	}
@@ -898,11 +967,11 @@ func (f *cmdFilter) Apply() error {
 	for _, input := range f.inputs {
 		iStats := input.Stats()
 		for pid, s := range iStats.pid2Stat {
-			pat, _ := s.Cmd()
+			st, err := s.State()
 			if err != nil {
 				continue
 			}
-			if f.pat.matchString(pat) {
+			if f.pat.matchString(st) {
 				f.pid2Stat[pid] = s
 			}
 		}
@@ -910,8 +979,8 @@ func (f *cmdFilter) Apply() error {
 	return nil
 }
 
-func (f *cmdFilter) Parse(p *Parser) error {
-	// eg: name("apa.*",all)
+func (f *stateFilter) Parse(p *Parser) error {
+	// eg: state('D|Z'r,all)
 	err := p.parseArgStregexp(&f.pat)
 	if err != nil {
 		return err
@@ -923,18 +992,17 @@ func (f *cmdFilter) Parse(p *Parser) error {
 	return p.parseSymbol(')')
 }
 
-func (f *cmdFilter) Stats() *stats {
+func (f *stateFilter) Stats() *stats {
 	return &f.stats
 }
 
-// Select matching exe name (full path to command with dirname and basename).
-type exeFilter struct {
+// Select processes using a realtime scheduling policy (fifo, rr or deadline).
+type realtimeFilter struct {
 	stats
-	pat    *stregexp
 	inputs []filter
 }
 
-func (f *exeFilter) Apply() error {
+func (f *realtimeFilter) Apply() error {
 	if !f.stats.reset() {
 		return nil
 	}
@@ -945,11 +1013,11 @@ func (f *exeFilter) Apply() error {
 	for _, input := range f.inputs {
 		iStats := input.Stats()
 		for pid, s := range iStats.pid2Stat {
-			pat, _ := s.Exe()
+			sp, err := s.SchedPolicy()
 			if err != nil {
 				continue
 			}
-			if f.pat.matchString(pat) {
+			if sp == "fifo" || sp == "rr" || sp == "deadline" {
 				f.pid2Stat[pid] = s
 			}
 		}
@@ -957,9 +1025,57 @@ func (f *exeFilter) Apply() error {
 	return nil
 }
 
-func (f *exeFilter) Parse(p *Parser) error {
-	// eg: name("apa.*",all)
-	err := p.parseArgStregexp(&f.pat)
+func (f *realtimeFilter) Parse(p *Parser) error {
+	// eg: realtime(all)
+	err := p.parseArgFilterList(&f.inputs, 0)
+	if err != nil {
+		return err
+	}
+	return p.parseSymbol(')')
+}
+
+func (f *realtimeFilter) Stats() *stats {
+	return &f.stats
+}
+
+// Select processes by comparing their nice level.
+type niceFilter struct {
+	stats
+	op     string // comparison operator
+	nice   int64
+	inputs []filter
+}
+
+func (f *niceFilter) Apply() error {
+	if !f.stats.reset() {
+		return nil
+	}
+	err := applyAll(f.inputs)
+	if err != nil {
+		return err
+	}
+	for _, input := range f.inputs {
+		iStats := input.Stats()
+		for pid, s := range iStats.pid2Stat {
+			v, err := s.Nice()
+			if err != nil {
+				continue
+			}
+			if compareInt64(v, f.op, f.nice) {
+				f.pid2Stat[pid] = s
+			}
+		}
+	}
+	return nil
+}
+
+func (f *niceFilter) Parse(p *Parser) error {
+	// eg: nice(<,0,all)
+	err := p.parseArgCompare(&f.op)
+	if err != nil {
+		return err
+	}
+	err = p.parseArgInt(&f.nice)
 	if err != nil {
 		return err
 	}
@@ -970,7 +1086,643 @@ func (f *exeFilter) Parse(p *Parser) error {
 	return p.parseSymbol(')')
 }
 
-func (f *exeFilter) Stats() *stats {
+func (f *niceFilter) Stats() *stats {
+	return &f.stats
+}
+
+// Select processes younger or older than a duration. (A pack age is the age of its oldest process)
+type ageFilter struct {
+	stats
+	younger bool
+	age     uint64 // in seconds
+	inputs  []filter
+}
+
+func (f *ageFilter) Apply() error {
+	if !f.stats.reset() {
+		return nil
+	}
+	err := applyAll(f.inputs)
+	if err != nil {
+		return err
+	}
+	for _, input := range f.inputs {
+		iStats := input.Stats()
+		for pid, s := range iStats.pid2Stat {
+			v, err := s.Age()
+			if err != nil {
+				continue
+			}
+			if (f.younger && v < f.age) || (!f.younger && v > f.age) {
+				f.pid2Stat[pid] = s
+			}
+		}
+	}
+	return nil
+}
+
+func (f *ageFilter) Parse(p *Parser) error {
+	// eg: older(1d,all)
+	var age int64
+	err := p.parseArgDuration(&age)
+	if err != nil {
+		return err
+	}
+	f.age = uint64(age)
+	err = p.parseArgFilterList(&f.inputs, 0)
+	if err != nil {
+		return err
+	}
+	return p.parseSymbol(')')
+}
+
+func (f *ageFilter) Stats() *stats {
+	return &f.stats
+}
+
+// Select processes holding deleted files (disk space that is freed only when the files are closed).
+type holdsDeletedFilter struct {
+	stats
+	inputs []filter
+}
+
+func (f *holdsDeletedFilter) Apply() error {
+	if !f.stats.reset() {
+		return nil
+	}
+	err := applyAll(f.inputs)
+	if err != nil {
+		return err
+	}
+	for _, input := range f.inputs {
+		iStats := input.Stats()
+		for pid, s := range iStats.pid2Stat {
+			v, err := s.DeletedFiles()
+			if err != nil {
+				continue
+			}
+			if v > 0 {
+				f.pid2Stat[pid] = s
+			}
+		}
+	}
+	return nil
+}
+
+func (f *holdsDeletedFilter) Parse(p *Parser) error {
+	// eg: holds_deleted(user('apache'))
+	err := p.parseArgFilterList(&f.inputs, 0)
+	if err != nil {
+		return err
+	}
+	return p.parseSymbol(')')
+}
+
+func (f *holdsDeletedFilter) Stats() *stats {
+	return &f.stats
+}
+
+// Expand processes into their threads (tasks).
+type threadsFilter struct {
+	stats
+	inputs []filter
+}
+
+func (f *threadsFilter) Apply() error {
+	if !f.stats.reset() {
+		return nil
+	}
+	err := applyAll(f.inputs)
+	if err != nil {
+		return err
+	}
+	var threads []*procStat
+	for _, ps := range unpackFiltersAsSlice(f.inputs, nil) {
+		if ps.status == DEAD || ps.IsThread() {
+			continue
+		}
+		threads = threadStats(ps, threads[:0])
+		for _, ts := range threads {
+			f.pid2Stat[ts.pid] = ts
+		}
+	}
+	return nil
+}
+
+func (f *threadsFilter) Parse(p *Parser) error {
+	// eg: threads(cmd('java'))
+	err := p.parseArgFilterList(&f.inputs, 0)
+	if err != nil {
+		return err
+	}
+	return p.parseSymbol(')')
+}
+
+func (f *threadsFilter) Stats() *stats {
+	return &f.stats
+}
+
+// Select processes listening on a TCP or UDP port.
+type listenFilter struct {
+	stats
+	pat    *stregexp
+	inputs []filter
+}
+
+func (f *listenFilter) Apply() error {
+	if !f.stats.reset() {
+		return nil
+	}
+	err := applyAll(f.inputs)
+	if err != nil {
+		return err
+	}
+	for _, input := range f.inputs {
+		iStats := input.Stats()
+		for pid, s := range iStats.pid2Stat {
+			lps, err := s.ListenPorts()
+			if err != nil {
+				continue
+			}
+			for _, lp := range lps {
+				if f.pat.matchString(strconv.Itoa(int(lp.port))) {
+					f.pid2Stat[pid] = s
+					break
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (f *listenFilter) Parse(p *Parser) error {
+	// eg: listen(8080,all)
+	// eg: listen('80|443'r)
+	tok, lit := p.scanIgnoreWhitespace()
+	if tok == tTNumber {
+		f.pat, _ = NewStregexp(lit, false, false)
+		err := p.parseArgSep()
+		if err != nil {
+			return err
+		}
+	} else {
+		p.unscan()
+		err := p.parseArgStregexp(&f.pat)
+		if err != nil {
+			return err
+		}
+	}
+	err := p.parseArgFilterList(&f.inputs, 0)
+	if err != nil {
+		return err
+	}
+	return p.parseSymbol(')')
+}
+
+func (f *listenFilter) Stats() *stats {
+	return &f.stats
+}
+
+/* Filters related to the command line (exe, cmdline)
+ */
+
+// Select matching command name (basename).
+type cmdFilter struct {
+	stats
+	pat    *stregexp
+	inputs []filter
+}
+
+func (f *cmdFilter) Apply() error {
+	if !f.stats.reset() {
+		return nil
+	}
+	err := applyAll(f.inputs)
+	if err != nil {
+		return err
+	}
+	for _, input := range f.inputs {
+		iStats := input.Stats()
+		for pid, s := range iStats.pid2Stat {
+			pat, _ := s.Cmd()
+			if err != nil {
+				continue
+			}
+			if f.pat.matchString(pat) {
+				f.pid2Stat[pid] = s
+			}
+		}
+	}
+	return nil
+}
+
+func (f *cmdFilter) Parse(p *Parser) error {
+	// eg: name("apa.*",all)
+	err := p.parseArgStregexp(&f.pat)
+	if err != nil {
+		return err
+	}
+	err = p.parseArgFilterList(&f.inputs, 0)
+	if err != nil {
+		return err
+	}
+	return p.parseSymbol(')')
+}
+
+func (f *cmdFilter) Stats() *stats {
+	return &f.stats
+}
+
+// Select matching exe name (full path to command with dirname and basename).
+type exeFilter struct {
+	stats
+	pat    *stregexp
+	inputs []filter
+}
+
+func (f *exeFilter) Apply() error {
+	if !f.stats.reset() {
+		return nil
+	}
+	err := applyAll(f.inputs)
+	if err != nil {
+		return err
+	}
+	for _, input := range f.inputs {
+		iStats := input.Stats()
+		for pid, s := range iStats.pid2Stat {
+			pat, _ := s.Exe()
+			if err != nil {
+				continue
+			}
+			if f.pat.matchString(pat) {
+				f.pid2Stat[pid] = s
+			}
+		}
+	}
+	return nil
+}
+
+func (f *exeFilter) Parse(p *Parser) error {
+	// eg: name("apa.*",all)
+	err := p.parseArgStregexp(&f.pat)
+	if err != nil {
+		return err
+	}
+	err = p.parseArgFilterList(&f.inputs, 0)
+	if err != nil {
+		return err
+	}
+	return p.parseSymbol(')')
+}
+
+func (f *exeFilter) Stats() *stats {
+	return &f.stats
+}
+
+// Select matching current working directory.
+type cwdFilter struct {
+	stats
+	pat    *stregexp
+	inputs []filter
+}
+
+func (f *cwdFilter) Apply() error {
+	if !f.stats.reset() {
+		return nil
+	}
+	err := applyAll(f.inputs)
+	if err != nil {
+		return err
+	}
+	for _, input := range f.inputs {
+		iStats := input.Stats()
+		for pid, s := range iStats.pid2Stat {
+			cwd, _ := s.Cwd()
+			if cwd == "" {
+				continue // Unknown (eg: process of another user).
+			}
+			if f.pat.matchString(cwd) {
+				f.pid2Stat[pid] = s
+			}
+		}
+	}
+	return nil
+}
+
+func (f *cwdFilter) Parse(p *Parser) error {
+	// eg: cwd('/srv/app'r)
+	err := p.parseArgStregexp(&f.pat)
+	if err != nil {
+		return err
+	}
+	err = p.parseArgFilterList(&f.inputs, 0)
+	if err != nil {
+		return err
+	}
+	return p.parseSymbol(')')
+}
+
+func (f *cwdFilter) Stats() *stats {
+	return &f.stats
+}
+
+// Select processes in a matching cgroup.
+type cgroupFilter struct {
+	stats
+	pat    *stregexp
+	inputs []filter
+}
+
+func (f *cgroupFilter) Apply() error {
+	if !f.stats.reset() {
+		return nil
+	}
+	err := applyAll(f.inputs)
+	if err != nil {
+		return err
+	}
+	for _, input := range f.inputs {
+		iStats := input.Stats()
+		for pid, s := range iStats.pid2Stat {
+			cg, _ := s.Cgroup()
+			if cg == "" {
+				continue
+			}
+			if f.pat.matchString(cg) {
+				f.pid2Stat[pid] = s
+			}
+		}
+	}
+	return nil
+}
+
+func (f *cgroupFilter) Parse(p *Parser) error {
+	// eg: cgroup('/system.slice/nginx'r)
+	err := p.parseArgStregexp(&f.pat)
+	if err != nil {
+		return err
+	}
+	err = p.parseArgFilterList(&f.inputs, 0)
+	if err != nil {
+		return err
+	}
+	return p.parseSymbol(')')
+}
+
+func (f *cgroupFilter) Stats() *stats {
+	return &f.stats
+}
+
+// Select processes in a matching systemd unit.
+type unitFilter struct {
+	stats
+	pat    *stregexp
+	inputs []filter
+}
+
+func (f *unitFilter) Apply() error {
+	if !f.stats.reset() {
+		return nil
+	}
+	err := applyAll(f.inputs)
+	if err != nil {
+		return err
+	}
+	for _, input := range f.inputs {
+		iStats := input.Stats()
+		for pid, s := range iStats.pid2Stat {
+			v, _ := s.Unit()
+			if v == "" {
+				continue // Not managed by systemd.
+			}
+			if f.pat.matchString(v) {
+				f.pid2Stat[pid] = s
+			}
+		}
+	}
+	return nil
+}
+
+func (f *unitFilter) Parse(p *Parser) error {
+	// eg: unit('nginx.service')
+	err := p.parseArgStregexp(&f.pat)
+	if err != nil {
+		return err
+	}
+	err = p.parseArgFilterList(&f.inputs, 0)
+	if err != nil {
+		return err
+	}
+	return p.parseSymbol(')')
+}
+
+func (f *unitFilter) Stats() *stats {
+	return &f.stats
+}
+
+// Select processes in a matching systemd slice.
+type sliceFilter struct {
+	stats
+	pat    *stregexp
+	inputs []filter
+}
+
+func (f *sliceFilter) Apply() error {
+	if !f.stats.reset() {
+		return nil
+	}
+	err := applyAll(f.inputs)
+	if err != nil {
+		return err
+	}
+	for _, input := range f.inputs {
+		iStats := input.Stats()
+		for pid, s := range iStats.pid2Stat {
+			v, _ := s.Slice()
+			if v == "" {
+				continue // Not managed by systemd.
+			}
+			if f.pat.matchString(v) {
+				f.pid2Stat[pid] = s
+			}
+		}
+	}
+	return nil
+}
+
+func (f *sliceFilter) Parse(p *Parser) error {
+	// eg: slice('^user-'r)
+	err := p.parseArgStregexp(&f.pat)
+	if err != nil {
+		return err
+	}
+	err = p.parseArgFilterList(&f.inputs, 0)
+	if err != nil {
+		return err
+	}
+	return p.parseSymbol(')')
+}
+
+func (f *sliceFilter) Stats() *stats {
+	return &f.stats
+}
+
+// Select processes in a container with a matching name or ID (full or short 12 digits ID).
+type containerFilter struct {
+	stats
+	pat    *stregexp
+	inputs []filter
+}
+
+func (f *containerFilter) Apply() error {
+	if !f.stats.reset() {
+		return nil
+	}
+	err := applyAll(f.inputs)
+	if err != nil {
+		return err
+	}
+	for _, input := range f.inputs {
+		iStats := input.Stats()
+		for pid, s := range iStats.pid2Stat {
+			id, _ := s.ContainerID()
+			if id == "" {
+				continue // Not in a container.
+			}
+			name, _ := s.ContainerName()
+			if f.pat.matchString(name) || f.pat.matchString(id) || len(id) > 12 && f.pat.matchString(id[:12]) {
+				f.pid2Stat[pid] = s
+			}
+		}
+	}
+	return nil
+}
+
+func (f *containerFilter) Parse(p *Parser) error {
+	// eg: container('web')
+	// eg: container('.*'r)
+	err := p.parseArgStregexp(&f.pat)
+	if err != nil {
+		return err
+	}
+	err = p.parseArgFilterList(&f.inputs, 0)
+	if err != nil {
+		return err
+	}
+	return p.parseSymbol(')')
+}
+
+func (f *containerFilter) Stats() *stats {
+	return &f.stats
+}
+
+// Select processes in a kubernetes pod with a matching name or namespace/name.
+type podFilter struct {
+	stats
+	pat    *stregexp
+	inputs []filter
+}
+
+func (f *podFilter) Apply() error {
+	if !f.stats.reset() {
+		return nil
+	}
+	err := applyAll(f.inputs)
+	if err != nil {
+		return err
+	}
+	for _, input := range f.inputs {
+		iStats := input.Stats()
+		for pid, s := range iStats.pid2Stat {
+			pod := s.Var("k8s_pod")
+			if pod == "" {
+				continue // Not in a pod (or unknown pod).
+			}
+			if f.pat.matchString(pod) || f.pat.matchString(s.Var("k8s_namespace")+"/"+pod) {
+				f.pid2Stat[pid] = s
+			}
+		}
+	}
+	return nil
+}
+
+func (f *podFilter) Parse(p *Parser) error {
+	// eg: pod('kube-system/coredns-'r)
+	err := p.parseArgStregexp(&f.pat)
+	if err != nil {
+		return err
+	}
+	err = p.parseArgFilterList(&f.inputs, 0)
+	if err != nil {
+		return err
+	}
+	return p.parseSymbol(')')
+}
+
+func (f *podFilter) Stats() *stats {
+	return &f.stats
+}
+
+// Select processes in a given namespace, or by default in a namespace other than the procfilter one (eg: containerized processes).
+type namespaceFilter struct {
+	stats
+	kind   string // pidns, netns, mntns, userns or utsns
+	inode  int64  // 0 means any namespace but the procfilter one.
+	inputs []filter
+}
+
+func (f *namespaceFilter) Apply() error {
+	if !f.stats.reset() {
+		return nil
+	}
+	err := applyAll(f.inputs)
+	if err != nil {
+		return err
+	}
+	k := nsKindNames[f.kind]
+	self := getSelfNS(k)
+	for _, input := range f.inputs {
+		iStats := input.Stats()
+		for pid, s := range iStats.pid2Stat {
+			ns, _ := s.NS(k)
+			if ns == 0 {
+				continue // Unknown.
+			}
+			if f.inode == 0 && ns != self || f.inode != 0 && ns == uint64(f.inode) {
+				f.pid2Stat[pid] = s
+			}
+		}
+	}
+	return nil
+}
+
+func (f *namespaceFilter) Parse(p *Parser) error {
+	// eg: namespace(pidns)
+	// eg: namespace(netns,4026532281,cmd('nginx'))
+	err := p.parseArgIdentifier(&f.kind)
+	if err != nil {
+		return err
+	}
+	if _, known := nsKindNames[f.kind]; !known {
+		return p.syntaxError(fmt.Sprintf("unknown namespace %q (expecting pidns, netns, mntns, userns or utsns)", f.kind))
+	}
+	tok, _ := p.scanIgnoreWhitespace()
+	p.unscan()
+	if tok == tTNumber {
+		err = p.parseArgInt(&f.inode)
+		if err != nil {
+			return err
+		}
+	}
+	err = p.parseArgFilterList(&f.inputs, 0)
+	if err != nil {
+		return err
+	}
+	return p.parseSymbol(')')
+}
+
+func (f *namespaceFilter) Stats() *stats {
 	return &f.stats
 }
 
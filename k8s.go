@@ -0,0 +1,186 @@
+package procfilter
+
+/* Kubernetes pods.
+The pod UID and QoS class are found in the kubepods cgroup path of a process. The pod name and namespace are read from the local kubelet files:
+- /var/log/pods/[namespace]_[pod]_[uid]/[container]/ (the pods log directories)
+- or else /var/lib/kubelet/pods/[uid]/ (the namespace of the service account volume and the hostname of the etc-hosts file)
+They are exposed as variables (see k8sVarNames) usable by tag, field, packby, ...
+*/
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Root directories of the kubelet files.
+var podsLogRoot = "/var/log/pods"
+var kubeletPodsRoot = "/var/lib/kubelet/pods"
+
+// Names of the kubernetes variables.
+var k8sVarNames = map[string]interface{}{
+	"k8s_namespace": nil,
+	"k8s_pod":       nil,
+	"k8s_pod_uid":   nil,
+	"k8s_qos":       nil,
+	"k8s_container": nil,
+}
+
+// k8sPodFromCgroup returns the pod UID and QoS class (guaranteed, burstable or besteffort) found in a kubepods cgroup path.
+// eg: /kubepods/burstable/pod0f3c9b2e-.../0123...cdef => 0f3c9b2e-..., burstable
+// eg: /kubepods.slice/kubepods-besteffort.slice/kubepods-besteffort-pod0f3c9b2e_....slice/cri-containerd-0123...cdef.scope => 0f3c9b2e-..., besteffort
+// eg: /kubepods.slice/kubepods-pod0f3c9b2e_....slice/crio-0123...cdef.scope => 0f3c9b2e-..., guaranteed
+func k8sPodFromCgroup(cgroup string) (uid, qos string) {
+	for _, c := range strings.Split(cgroup, "/") {
+		c = strings.TrimSuffix(c, ".slice")
+		if c == "kubepods" || strings.HasSuffix(c, "-kubepods") { // eg: kubelet-kubepods
+			qos = "guaranteed" // Guaranteed pods are directly under kubepods.
+			continue
+		}
+		if qos == "" {
+			continue // Not under kubepods yet.
+		}
+		if i := strings.LastIndex(c, "pod"); i == 0 || i > 0 && c[i-1] == '-' {
+			// The systemd cgroup driver replaces the - of the UID by _.
+			return strings.Replace(c[i+3:], "_", "-", -1), qos
+		}
+		if c == "burstable" || strings.HasSuffix(c, "-burstable") {
+			qos = "burstable"
+		} else if c == "besteffort" || strings.HasSuffix(c, "-besteffort") {
+			qos = "besteffort"
+		}
+	}
+	return "", ""
+}
+
+// A pod as found in the kubelet files.
+type podInfo struct {
+	namespace  string
+	name       string
+	containers []string // Names of the containers (from the log directories).
+}
+
+// Known pods (indexed by UID). The pods log directory is read again (at most once per sample) when an unknown pod is found.
+var podInfos map[string]*podInfo
+var podInfosTs tStamp
+
+// getPodInfo returns the pod with the given UID (nil if not found).
+func getPodInfo(uid string) *podInfo {
+	pi, known := podInfos[uid]
+	if known || podInfos != nil && podInfosTs == stamp {
+		return pi
+	}
+	podInfosTs = stamp
+	podInfos = readPodsLog()
+	pi, known = podInfos[uid]
+	if !known {
+		pi = readKubeletPod(uid)
+		if pi != nil {
+			podInfos[uid] = pi
+		}
+	}
+	return pi
+}
+
+// readPodsLog reads the pods found in the pods log directory.
+func readPodsLog() map[string]*podInfo {
+	pis := map[string]*podInfo{}
+	d, err := os.Open(podsLogRoot)
+	if err != nil {
+		return pis
+	}
+	fnames, _ := d.Readdirnames(-1)
+	d.Close()
+	for _, fname := range fnames {
+		// eg: kube-system_coredns-5d78c9869d-8xq2p_0f3c9b2e-1b2a-4c3d-9e8f-0123456789ab (namespaces and pod names can not contain a _).
+		f := strings.Split(fname, "_")
+		if len(f) != 3 {
+			continue
+		}
+		pi := &podInfo{namespace: f[0], name: f[1]}
+		if cd, err := os.Open(filepath.Join(podsLogRoot, fname)); err == nil {
+			pi.containers, _ = cd.Readdirnames(-1)
+			cd.Close()
+		}
+		pis[f[2]] = pi
+	}
+	return pis
+}
+
+// readKubeletPod reads a pod from the kubelet pods directory (used when the pods log directory is missing).
+func readKubeletPod(uid string) *podInfo {
+	dir := filepath.Join(kubeletPodsRoot, uid)
+	pi := &podInfo{}
+	// The namespace is found in the service account token volume (eg: volumes/kubernetes.io~projected/kube-api-access-xxxxx/namespace).
+	fns, _ := filepath.Glob(filepath.Join(dir, "volumes", "kubernetes.io~*", "*", "namespace"))
+	for _, fn := range fns {
+		b, err := ioutil.ReadFile(fn)
+		if err == nil {
+			pi.namespace = strings.TrimSpace(string(b))
+			break
+		}
+	}
+	// The pod hostname (its name unless spec.hostname is set) is on the last line of the etc-hosts file.
+	b, err := ioutil.ReadFile(filepath.Join(dir, "etc-hosts"))
+	if err == nil {
+		lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+		f := strings.Fields(lines[len(lines)-1])
+		if len(f) > 1 && f[0] != "127.0.0.1" {
+			pi.name = f[1]
+		}
+	}
+	if cd, err := os.Open(filepath.Join(dir, "containers")); err == nil {
+		pi.containers, _ = cd.Readdirnames(-1)
+		cd.Close()
+	}
+	if pi.namespace == "" && pi.name == "" {
+		return nil
+	}
+	return pi
+}
+
+// k8sContainer returns the kubernetes name of the container of a process.
+func (ps *procStat) k8sContainer(pi *podInfo) string {
+	name, _ := ps.ContainerName()
+	if strings.HasPrefix(name, "k8s_") {
+		// docker and cri-o names: k8s_[container]_[pod]_[namespace]_[uid]_[attempt]
+		f := strings.Split(name, "_")
+		return f[1]
+	}
+	if name != "" && name != ps.containerID {
+		return name // containerd (CRI) name.
+	}
+	if pi != nil && len(pi.containers) == 1 {
+		return pi.containers[0] // Single container pod.
+	}
+	return ""
+}
+
+// k8sVar returns the value of one of the kubernetes variables ("" if the process is not in a pod).
+func (ps *procStat) k8sVar(name string) string {
+	ps.updateFromCgroup()
+	if ps.podUID == "" {
+		return ""
+	}
+	switch name {
+	case "k8s_pod_uid":
+		return ps.podUID
+	case "k8s_qos":
+		return ps.podQoS
+	}
+	pi := getPodInfo(ps.podUID)
+	switch name {
+	case "k8s_container":
+		return ps.k8sContainer(pi)
+	case "k8s_namespace":
+		if pi != nil {
+			return pi.namespace
+		}
+	case "k8s_pod":
+		if pi != nil {
+			return pi.name
+		}
+	}
+	return ""
+}
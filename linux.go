@@ -11,6 +11,8 @@ import (
 	"io"
 	"io/ioutil"
 	"os"
+	"strings"
+	"syscall"
 	"time"
 )
 
@@ -109,7 +111,6 @@ func fastReadLine() []byte {
 		}
 		bi++
 	}
-	return nil
 }
 
 // fastRead reads a short file using a single static common buffer.
@@ -152,6 +153,44 @@ func fastParseUint64(s []byte, i int) (res uint64, index int) {
 	return res, i
 }
 
+// fastParseInt64 parses a signed integer (eg: nice value in /proc/[pid]/stat).
+// WARNING: to be fast this function assumes that we are on the sign or first digit of the integer to parse.
+func fastParseInt64(s []byte, i int) (res int64, index int) {
+	if i < len(s) && s[i] == '-' {
+		v, index := fastParseUint64(s, i+1)
+		return -int64(v), index
+	}
+	v, index := fastParseUint64(s, i)
+	return int64(v), index
+}
+
+// fastParseNextUint64 skips the non digit chars (eg: the spaces after a 'key:' in /proc/[pid]/status) and parses the next integer. Stops at the end of the line if no digit is found.
+func fastParseNextUint64(s []byte, i int) (res uint64, index int) {
+	sl := len(s)
+	for ; i < sl; i++ {
+		if '0' <= s[i] && s[i] <= '9' {
+			return fastParseUint64(s, i)
+		}
+		if s[i] == '\n' {
+			break
+		}
+	}
+	return 0, i
+}
+
+// fastHasPrefix checks if the key is found in s at index i.
+func fastHasPrefix(s []byte, i int, key string) bool {
+	if i+len(key) > len(s) {
+		return false
+	}
+	for j := 0; j < len(key); j++ {
+		if s[i+j] != key[j] {
+			return false
+		}
+	}
+	return true
+}
+
 // Search s for the previous integer.
 // Assumes we are scaning a file structured like /proc/[pid]/status where we have lines like: key : value.
 // In this case we assume i is pointing between the EOL and last digit of the interger and value is an int followed by an optional unit. eg: VmSwap:	     384 kB
@@ -277,18 +316,38 @@ func (ps *procStat) initFromStat() bool {
 			resstr, i = fastParseUntil(s, i, ')')
 			ps.cmd = resstr
 			//trace("pid=%d cmd=%s", ps.pid, ps.cmd)
+		case 2: // 2 state (R,S,D,Z,T,...)
+			i++ // Skip the ' '.
+			ps.state = s[i]
+			i++
 		case 3: // 3 ppid
 			res, i = fastParseUint64(s, i)
 			ps.ppid = tPid(res)
 		/*case 4: // 4 pgrp
 		pgrp, i = fastParseInt(s, i) // used to detect kernel threads
 		*/
+		case 9: // min_flt number of minor faults
+			res, i = fastParseUint64(s, i)
+			ps.minFlt = res
+		case 11: // maj_flt number of major faults
+			res, i = fastParseUint64(s, i)
+			ps.majFlt = res
 		case 13: // utime is number of jiffies used by this process in user mode.
 			res, i = fastParseUint64(s, i)
+			ps.utime = res
 			ps.cpu = res
 		case 14: // stime is number of jiffies used by this process in system mode.
 			res, i = fastParseUint64(s, i)
+			ps.stime = res
 			ps.cpu += res // aggregate sys+user jiffies.
+		case 17: // priority (negative for realtime processes)
+			var v int64
+			v, i = fastParseInt64(s, i)
+			ps.priority = int32(v)
+		case 18: // nice level (-20..19)
+			var v int64
+			v, i = fastParseInt64(s, i)
+			ps.nice = int32(v)
 		case 19: // num_threads   number of threads
 			res, i = fastParseUint64(s, i)
 			ps.threadNb = uint32(res)
@@ -302,6 +361,18 @@ func (ps *procStat) initFromStat() bool {
 			res, i = fastParseUint64(s, i)
 			ps.rss = res * PageSize
 			//ps.trace(4)
+		case 38: // task_cpu CPU the process last ran on
+			res, i = fastParseUint64(s, i)
+			ps.processor = int32(res)
+		case 39: // rt_priority realtime priority (1..99, 0 if not realtime)
+			res, i = fastParseUint64(s, i)
+			ps.rtPriority = uint32(res)
+		case 40: // policy scheduling policy
+			res, i = fastParseUint64(s, i)
+			ps.policy = uint32(res)
+		case 41: // delayacct_blkio_ticks time spent waiting for block IO
+			res, i = fastParseUint64(s, i)
+			ps.blkio = res
 			return true // Last field we need to parse.
 		default: // Skip this field.
 			i++
@@ -314,7 +385,7 @@ func (ps *procStat) initFromStat() bool {
 		// Assume one and only one ' '  between fields.
 		f++
 	}
-	return false // Did not parse upto the last required field. This should not be possible but ...
+	return f > 23 // Stopped before the last field. Keep the process if we got the required ones (upto rss).
 }
 
 // updateFromStat using the content of /proc/[pid]/stat, updates some stats about this process that will be used later (during Gather). The idea is to refresh some sttas that are considered critical if the process dies before the next Gather (eg: cpu usage).
@@ -334,12 +405,38 @@ func (ps *procStat) updateFromStat() error {
 	var res uint64 // holds the return value of fastParseInt
 	for i := 0; i < sl; i++ {
 		switch f {
+		case 1: // 1 tcomm may contain spaces, skip upto the ')'.
+			for ; i < sl; i++ {
+				if s[i] == ')' {
+					break
+				}
+			}
+		case 2: // 2 state (R,S,D,Z,T,...)
+			i++ // Skip the ' '.
+			ps.state = s[i]
+			i++
+		case 9: // min_flt number of minor faults
+			res, i = fastParseUint64(s, i)
+			ps.minFlt = res
+		case 11: // maj_flt number of major faults
+			res, i = fastParseUint64(s, i)
+			ps.majFlt = res
 		case 13: // utime is number of jiffies used by this process in user mode.
 			res, i = fastParseUint64(s, i)
+			ps.utime = res
 			ps.cpu = res
 		case 14: // stime is number of jiffies used by this process in system mode.
 			res, i = fastParseUint64(s, i)
+			ps.stime = res
 			ps.cpu += res // aggregate sys+user jiffies.
+		case 17: // priority (negative for realtime processes)
+			var v int64
+			v, i = fastParseInt64(s, i)
+			ps.priority = int32(v)
+		case 18: // nice level (-20..19)
+			var v int64
+			v, i = fastParseInt64(s, i)
+			ps.nice = int32(v)
 		case 19: // num_threads   number of threads
 			res, i = fastParseUint64(s, i)
 			ps.threadNb = uint32(res)
@@ -349,6 +446,18 @@ func (ps *procStat) updateFromStat() error {
 		case 23: // rss
 			res, i = fastParseUint64(s, i)
 			ps.rss = res * PageSize
+		case 38: // task_cpu CPU the process last ran on
+			res, i = fastParseUint64(s, i)
+			ps.processor = int32(res)
+		case 39: // rt_priority realtime priority (1..99, 0 if not realtime)
+			res, i = fastParseUint64(s, i)
+			ps.rtPriority = uint32(res)
+		case 40: // policy scheduling policy (can be changed with chrt)
+			res, i = fastParseUint64(s, i)
+			ps.policy = uint32(res)
+		case 41: // delayacct_blkio_ticks time spent waiting for block IO
+			res, i = fastParseUint64(s, i)
+			ps.blkio = res
 			return nil // Last field we need to parse.
 		default: // Skip this field.
 			i++
@@ -364,7 +473,20 @@ func (ps *procStat) updateFromStat() error {
 	return nil
 }
 
-// Find some values (lswap, tgid, ...) found only in /proc/[pid]/status.
+// statusMemKey checks if a memKind key starts at index i and returns it with its length (0 if no key found).
+func statusMemKey(s []byte, i int) (memKind, int) {
+	if s[i] != 'V' && s[i] != 'R' && s[i] != 'H' {
+		return 0, 0 // Fast path: all keys start with one of these letters.
+	}
+	for k := memKind(0); k < memStatusNb; k++ {
+		if fastHasPrefix(s, i, memKindKeys[k]) {
+			return k, len(memKindKeys[k])
+		}
+	}
+	return 0, 0
+}
+
+// Find some values (lswap, tgid, detailed memory, context switches, ...) found only in /proc/[pid]/status.
 // The key: values change between kernel versions or process types (kernel workers vs process) so we have to search for the keys.
 func (ps *procStat) updateFromStatus() {
 	if ps.statusTs == stamp || ps.status == DEAD {
@@ -380,6 +502,7 @@ func (ps *procStat) updateFromStatus() {
 		ps.dead(0)
 		return
 	}
+	ps.statusTime = uint64(time.Now().UnixNano())
 	sol := true
 	var i int
 	var res uint64
@@ -424,9 +547,37 @@ func (ps *procStat) updateFromStatus() {
 						res, i = fastParseUint64(s, i)
 						ps.swap = res * 1024 // status contais the  swap in kB.
 						//trace("pid=%d tgid=%d swap=%d", ps.pid, ps.tgid, ps.swap)
-						return // swap is the last value we want to extract.}
+						break
+					}
+				}
+			} else if k, l := statusMemKey(s, i); l != 0 {
+				// eg: RssAnon:	  123456 kB
+				res, i = fastParseNextUint64(s, i+l)
+				ps.mem[k] = res * 1024
+			} else if s[i] == 'N' && fastHasPrefix(s, i, "NSpid:") {
+				// NSpid:	12345	1 (the PID in each nested PID namespace, the last one is the innermost)
+				i += 6
+				for i < sl && s[i] != '\n' {
+					if '0' <= s[i] && s[i] <= '9' {
+						res, i = fastParseUint64(s, i)
+						ps.nsPid = tPid(res)
+					} else {
+						i++
 					}
 				}
+			} else if s[i] == 'C' && fastHasPrefix(s, i, "Cpus_allowed_list:") {
+				// Cpus_allowed_list:	0-3,8
+				i += 18
+				for ; i < sl && (s[i] == ' ' || s[i] == '\t'); i++ {
+				}
+				ps.cpusAllowed, i = fastParseUntil(s, i, '\n')
+			} else if s[i] == 'v' && fastHasPrefix(s, i, "voluntary_ctxt_switches:") {
+				// voluntary_ctxt_switches:	2231
+				ps.volCtxSw, i = fastParseNextUint64(s, i+24)
+			} else if s[i] == 'n' && fastHasPrefix(s, i, "nonvoluntary_ctxt_switches:") {
+				// nonvoluntary_ctxt_switches:	13
+				ps.nvolCtxSw, i = fastParseNextUint64(s, i+27)
+				return // context switches are the last values we want to extract.
 			} else {
 				i++
 			}
@@ -446,8 +597,9 @@ func (ps *procStat) updateFromStatus() {
 	}
 }
 
-// PSS (Proportional Set Size) is available in /proc/[pid]/smaps.
-// We have to summ all Pss: lines.
+// PSS (Proportional Set Size), USS (Unique Set Size) and swap PSS are available in /proc/[pid]/smaps.
+// We have to summ all Pss:, Private_Clean:, Private_Dirty: and SwapPss: lines.
+// When the kernel provides /proc/[pid]/smaps_rollup (>= 4.14) we read it instead. It contains the same keys but already summed, thus it is a lot cheaper to read.
 func (ps *procStat) updateFromSmaps() {
 	// Verified with:
 	//   cd /proc; for PID in [0-9]*; do PSS=$(echo $(egrep "^Pss:" /proc/$PID/smaps 2>/dev/null | egrep -o "[0-9]+" | sed -r 's/$/+/' | tr -d '\n')0 | bc); if [[ $PSS != 0 ]]; then echo "$PSS kB : $PID : $(cat /proc/$PID/cmdline)"; fi ; done | sort -n
@@ -456,55 +608,250 @@ func (ps *procStat) updateFromSmaps() {
 	}
 	ps.smapsTs = stamp
 	if ps.pfnSmaps == "" {
-		ps.pfnSmaps = procFileName(ps.pid, "smaps")
+		if SmapsRollup {
+			ps.pfnSmaps = procFileName(ps.pid, "smaps_rollup")
+		} else {
+			ps.pfnSmaps = procFileName(ps.pid, "smaps")
+		}
 	}
 	err := fastReadOpen(ps.pfnSmaps)
 	if err != nil {
 		ps.dead(0)
 		return
 	}
-	var totpss int
-	var kw uint32 // The bytes from 'Pss:' can be stored on a single 32b word.
+	var pss, uss, swapPss, v uint64
 	for {
-		c, err := fastReadByte()
-		if err != nil {
+		l := fastReadLine()
+		if l == nil {
 			break
 		}
-		kw = (kw << 8) + uint32(c)
-		if kw != 0x5073733a { // echo -n "Pss:" | od -t x4 --endian=big
+		if len(l) == 0 {
 			continue
 		}
-		kw = 0
-		// We matched "Pss:", now search for the digits.
-		var pss int = 0
-	WSpaces:
-		c, err = fastReadByte()
-		if err != nil {
+		// Most lines are either a mapping header or a key we do not need, the first char is enough to skip them.
+		switch l[0] {
+		case 'P':
+			if fastHasPrefix(l, 0, "Pss:") {
+				// Pss:                 316 kB
+				v, _ = fastParseNextUint64(l, 4)
+				pss += v
+			} else if fastHasPrefix(l, 0, "Private_Clean:") || fastHasPrefix(l, 0, "Private_Dirty:") {
+				// Private_Dirty:        84 kB
+				v, _ = fastParseNextUint64(l, 14)
+				uss += v
+			}
+		case 'S':
+			if fastHasPrefix(l, 0, "SwapPss:") {
+				// SwapPss:               0 kB
+				v, _ = fastParseNextUint64(l, 8)
+				swapPss += v
+			}
+		}
+	}
+	ps.mem[memPss] = pss << 10 // kB to bytes <=> *1024 <=> left shift 10
+	ps.mem[memUss] = uss << 10
+	ps.mem[memSwapPss] = swapPss << 10
+}
+
+// updateFromNumaMaps sums the pages per NUMA node of all the mappings listed in /proc/[pid]/numa_maps.
+// Huge pages are counted as their equivalent number of PageSize pages.
+func (ps *procStat) updateFromNumaMaps() {
+	if ps.numaTs == stamp || ps.status == DEAD {
+		return
+	}
+	ps.numaTs = stamp
+	if ps.pfnNuma == "" {
+		ps.pfnNuma = procFileName(ps.pid, "numa_maps")
+	}
+	err := fastReadOpen(ps.pfnNuma)
+	if err != nil {
+		return // Not a NUMA kernel or access permission issues.
+	}
+	for n := range ps.numa {
+		ps.numa[n] = 0
+	}
+	var lnuma []uint64 // pages per node for the current line.
+	for {
+		l := fastReadLine()
+		if l == nil {
 			break
 		}
-		if c == ' ' {
-			goto WSpaces
+		// eg: 7f2c4c021000 default file=/usr/lib/libc.so.6 mapped=5 N0=3 N1=2 kernelpagesize_kB=4
+		ll := len(l)
+		lnuma = lnuma[:0]
+		var pageKB uint64 = 4
+		for i := 0; i < ll-2; i++ {
+			if l[i] != ' ' {
+				continue
+			}
+			// Start of a key.
+			if l[i+1] == 'N' && '0' <= l[i+2] && l[i+2] <= '9' {
+				var node, pages uint64
+				node, i = fastParseUint64(l, i+2)
+				if i >= ll || l[i] != '=' {
+					continue
+				}
+				pages, i = fastParseUint64(l, i+1)
+				for int(node) >= len(lnuma) {
+					lnuma = append(lnuma, 0)
+				}
+				lnuma[node] = pages
+				i-- // Let the loop see the space before the next key.
+			} else if l[i+1] == 'k' && fastHasPrefix(l, i+1, "kernelpagesize_kB=") {
+				pageKB, i = fastParseUint64(l, i+19)
+				i--
+			}
 		}
-		var unread = true
-	Digits:
-		if !unread {
-			c, err = fastReadByte()
-			if err != nil {
-				break
+		for n, pages := range lnuma {
+			if n >= len(ps.numa) {
+				ps.numa = append(ps.numa, 0)
 			}
+			ps.numa[n] += pages * (pageKB << 10) / PageSize
+		}
+	}
+}
+
+// updateFromSchedstat reads the run queue wait time from /proc/[pid]/schedstat.
+// The file contains 3 values: time spent on the cpu (ns), time spent waiting on a run queue (ns) and number of timeslices run on this cpu.
+// /proc/[pid]/schedstat is for the main thread only, so the values of all the threads (/proc/[pid]/task/*/schedstat) are summed (see addTaskWaits). A thread enumerated by a threads() filter reads its own file.
+func (ps *procStat) updateFromSchedstat() error {
+	if ps.status == DEAD {
+		return nil
+	}
+	if ps.thread {
+		wait, err := readSchedstatWait(ps.pfnSched)
+		if err != nil {
+			return err
+		}
+		ps.waitNs = wait
+		ps.schedTime = uint64(time.Now().UnixNano())
+		return nil
+	}
+	dir := procFileName(ps.pid, "task")
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	tids, err := d.Readdirnames(-1)
+	d.Close()
+	if err != nil {
+		return err
+	}
+	waits := make(map[string]uint64, len(tids))
+	for _, tid := range tids {
+		wait, err := readSchedstatWait(dir + "/" + tid + "/schedstat")
+		if err != nil {
+			continue // Thread already gone or kernel built without schedstats.
+		}
+		waits[tid] = wait
+	}
+	ps.addTaskWaits(waits)
+	ps.schedTime = uint64(time.Now().UnixNano())
+	return nil
+}
+
+// addTaskWaits adds to waitNs the run queue wait of the threads (indexed by TID) since the last read.
+// Summing the values of the live threads would decrease when a thread exits, so the deltas are summed instead: the wait time of the exited threads is kept and waitNs never decreases.
+func (ps *procStat) addTaskWaits(waits map[string]uint64) {
+	for tid, wait := range waits {
+		prev, known := ps.taskWaits[tid]
+		if known && wait >= prev {
+			ps.waitNs += wait - prev
 		} else {
-			unread = false
+			ps.waitNs += wait // New thread (or TID reused).
 		}
-		if (c >= '0') && (c <= '9') {
-			pss = pss*10 + int(c-'0')
-			goto Digits
+	}
+	ps.taskWaits = waits
+}
+
+// readSchedstatWait returns the time spent waiting on a run queue (ns) read from a schedstat file.
+func readSchedstatWait(fn string) (uint64, error) {
+	s, err := fastRead(fn)
+	sl := len(s)
+	if err != nil || sl == 0 {
+		// We cannot assume that the process is dead, the kernel may have been built without schedstats.
+		return 0, err
+	}
+	// eg: 1373539946 183468373 4621
+	i := findNextIndex(s, 0, ' ') + 1
+	if i >= sl {
+		return 0, nil
+	}
+	wait, _ := fastParseUint64(s, i)
+	return wait, nil
+}
+
+// updateFromOom reads the OOM killer scores from /proc/[pid]/oom_score and /proc/[pid]/oom_score_adj.
+func (ps *procStat) updateFromOom() error {
+	if ps.oomTs == stamp || ps.status == DEAD {
+		return nil
+	}
+	ps.oomTs = stamp
+	if ps.pfnOom == "" {
+		ps.pfnOom = procFileName(ps.pid, "oom_score")
+		ps.pfnOomAdj = procFileName(ps.pid, "oom_score_adj")
+	}
+	s, err := fastRead(ps.pfnOom)
+	if err != nil || len(s) == 0 {
+		ps.dead(0)
+		return err
+	}
+	ps.oomScore, _ = fastParseUint64(s, 0)
+	s, err = fastRead(ps.pfnOomAdj)
+	if err != nil || len(s) == 0 {
+		ps.dead(0)
+		return err
+	}
+	// eg: -1000
+	if s[0] == '-' {
+		adj, _ := fastParseUint64(s, 1)
+		ps.oomScoreAdj = -int64(adj)
+	} else {
+		adj, _ := fastParseUint64(s, 0)
+		ps.oomScoreAdj = int64(adj)
+	}
+	return nil
+}
+
+// updateFromLimits reads the soft limits for open files and processes from /proc/[pid]/limits.
+func (ps *procStat) updateFromLimits() error {
+	if ps.limitsTs == stamp || ps.status == DEAD {
+		return nil
+	}
+	ps.limitsTs = stamp
+	if ps.pfnLimits == "" {
+		ps.pfnLimits = procFileName(ps.pid, "limits")
+	}
+	s, err := fastRead(ps.pfnLimits)
+	sl := len(s)
+	if err != nil || sl == 0 {
+		// We cannot assume that the process is dead, maybe some access permssion issues?
+		return err
+	}
+	// Columns have a fixed width, the soft limit starts at index 26 of the line.
+	// Limit                     Soft Limit           Hard Limit           Units
+	// Max processes             63372                63372                processes
+	// Max open files            1024                 524288               files
+	for i := 0; i < sl; i++ {
+		if s[i] == 'M' && i+27 < sl {
+			if fastHasPrefix(s, i, "Max open files") {
+				ps.fdLimit = parseLimit(s, i+26)
+			} else if fastHasPrefix(s, i, "Max processes") {
+				ps.nprocLimit = parseLimit(s, i+26)
+			}
 		}
-		totpss += pss
-		// skip the "kB: sctring.
-		_, _ = fastReadByte()
-		_, _ = fastReadByte()
+		i = findNextIndex(s, i, '\n')
+	}
+	return nil
+}
+
+// parseLimit parses a limit value in /proc/[pid]/limits (0 means unlimited).
+func parseLimit(s []byte, i int) uint64 {
+	if s[i] == 'u' { // unlimited
+		return 0
 	}
-	ps.pss = uint64(totpss) << 10 // kB to bytes <=> *1024 <=> left shift 10
+	v, _ := fastParseUint64(s, i)
+	return v
 }
 
 // Init cmdline from the content of /proc/[pid]/cmdline. If cmdline is empty assume this is a kernel thread and fix its cmd value to remove all CPU related info.
@@ -537,11 +884,40 @@ func (ps *procStat) updateFromCmdline() error {
 		}
 		sl-- // The last char is a 0, skip its copy.
 		ps.cmdLine = string(s)
-		ps.exe = ps.cmdLine[0:ee] // TODO This assumes that we have one char per byte... fishy if not utf8/ascii...
+		ps.updateFromLinks()
+		if ps.exe == "" {
+			// The exe link is not readable (eg: process of another user), fallback to argv[0]. Not reliable for relative paths or processes rewriting their argv.
+			ps.exe = ps.cmdLine[0:ee]
+		}
 	}
 	return nil
 }
 
+// updateFromLinks reads the /proc/[pid]/exe and root symlinks. They are read once (and again after an exec).
+func (ps *procStat) updateFromLinks() {
+	if ps.linksRead || ps.status == DEAD {
+		return
+	}
+	ps.linksRead = true
+	if ps.exe == "" {
+		exe, err := os.Readlink(procFileName(ps.pid, "exe"))
+		if err == nil {
+			// eg: /usr/bin/java (deleted) when the binary was upgraded after the process start.
+			ps.exe = strings.TrimSuffix(exe, " (deleted)")
+		}
+	}
+	ps.root, _ = os.Readlink(procFileName(ps.pid, "root"))
+}
+
+// updateFromCwd reads the /proc/[pid]/cwd symlink, once per sample (the process may chdir at any time).
+func (ps *procStat) updateFromCwd() {
+	if ps.cwdTs == stamp || ps.status == DEAD {
+		return
+	}
+	ps.cwdTs = stamp
+	ps.cwd, _ = os.Readlink(procFileName(ps.pid, "cwd"))
+}
+
 // updateFromFd using the content of /proc/[pid]/fd/, updates the number of open files.
 func (ps *procStat) updateFromFd() error {
 	if ps.status == DEAD {
@@ -566,7 +942,85 @@ func (ps *procStat) updateFromFd() error {
 	return nil
 }
 
-// Get summ of IO Read/Write counters from /proc/#/io
+// updateFromFdTypes reads the target of every link in /proc/[pid]/fd/ to count the open files by type and find the deleted ones.
+// This is a lot more costly than updateFromFd (one readlink per open file).
+func (ps *procStat) updateFromFdTypes() error {
+	for k := range ps.fdTypes {
+		ps.fdTypes[k] = 0
+	}
+	ps.deleted = nil
+	ps.sockInodes = ps.sockInodes[:0]
+	if ps.status == DEAD {
+		return nil
+	}
+	path := procFileName(ps.pid, "fd")
+	d, err := os.Open(path)
+	if err != nil {
+		// We cannot assume that the process is dead, maybe some access permssion issues?
+		return err
+	}
+	names, err := d.Readdirnames(0)
+	d.Close()
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		fn := path + "/" + name
+		target, err := os.Readlink(fn)
+		if err != nil {
+			continue // Probably closed since we listed the directory.
+		}
+		k := fdTypeOf(target)
+		if k == fdSocket {
+			// eg: socket:[21586]
+			ino, _ := fastParseNextUint64([]byte(target), 7)
+			ps.sockInodes = append(ps.sockInodes, ino)
+		}
+		ps.fdTypes[k]++
+		if k != fdFile || !strings.HasSuffix(target, " (deleted)") {
+			continue
+		}
+		// eg: /var/log/app.log (deleted)
+		// Stat it through the fd link (this works even if the file has no name anymore).
+		fi, err := os.Stat(fn)
+		if err != nil {
+			continue
+		}
+		st, ok := fi.Sys().(*syscall.Stat_t)
+		if !ok {
+			continue
+		}
+		if ps.deleted == nil {
+			ps.deleted = map[[2]uint64]uint64{}
+		}
+		// Indexed by device and inode, a file may be opened more than once.
+		// The allocated blocks are the disk space really pinned (the apparent size is wrong for sparse or truncated files).
+		ps.deleted[[2]uint64{uint64(st.Dev), st.Ino}] = uint64(st.Blocks) * 512
+	}
+	return nil
+}
+
+// fdTypeOf returns the type of an open file using its /proc/[pid]/fd/ link target.
+// eg: socket:[21586], pipe:[21590], anon_inode:[eventpoll], /dev/null, /var/log/messages
+func fdTypeOf(target string) fdKind {
+	switch {
+	case strings.HasPrefix(target, "socket:"):
+		return fdSocket
+	case strings.HasPrefix(target, "pipe:"):
+		return fdPipe
+	case strings.HasPrefix(target, "anon_inode:"):
+		return fdAnon
+	case strings.HasPrefix(target, "/dev/") && !strings.HasPrefix(target, "/dev/shm/"):
+		return fdDevice
+	case strings.HasPrefix(target, "/memfd:"):
+		return fdOther
+	case strings.HasPrefix(target, "/"):
+		return fdFile
+	}
+	return fdOther
+}
+
+// Get the IO counters from /proc/#/io (and the sum of the Read/Write bytes counters)
 func (ps *procStat) updateFromIO() {
 	if ps.pfnIo == "" {
 		ps.pfnIo = procFileName(ps.pid, "io")
@@ -577,50 +1031,24 @@ func (ps *procStat) updateFromIO() {
 		ps.dead(0)
 		return
 	}
-	sol := true
-	var i int
-	var io uint64
-	for {
-		if sol {
-			if i+6 >= sl {
-				return // end of file.
-			}
-			// Doc in https://git.kernel.org/pub/scm/linux/kernel/git/torvalds/linux.git/tree/Documentation/filesystems/proc.txt?id=HEAD#l1305
-			// use these 2 metrics rather that rchar/wchar to track disk level activity (avoid counting memory cache accesses)
-			// read_bytes: 809781143552
-			// write_bytes: 4366930132992
-
-			sol = false
-			// Start of line. So next bytes are the key.
-			if s[i] == 'r' && s[i+1] == 'e' && s[i+5] == 'b' {
-				// read_bytes: 809781143552
-				i += 12
-				io, i = fastParseUint64(s, i)
-				//fmt.Printf("pid=%d ior=%d\n", ps.pid, io)
-			} else if s[i] == 'w' && s[i+1] == 'r' && s[i+6] == 'b' {
-				// write_bytes: 4366930132992
-				var r uint64
-				i += 13
-				r, i = fastParseUint64(s, i)
-				//fmt.Printf("pid=%d ior=%d iow=%d\n", ps.pid, r, io)
-				ps.io = io + r
-				return
-			} else {
-				i++
-			}
-		} else {
-			i++
-		}
-		if i >= sl {
-			return // end of file, swap not found.
-		}
-		if s[i] == '\n' {
-			i++
-			if i >= sl {
-				return // end of file, swap not found.
+	// Doc in https://git.kernel.org/pub/scm/linux/kernel/git/torvalds/linux.git/tree/Documentation/filesystems/proc.txt?id=HEAD#l1305
+	// rchar: 323934931
+	// wchar: 323929600
+	// syscr: 632687
+	// syscw: 632675
+	// read_bytes: 809781143552
+	// write_bytes: 4366930132992
+	// cancelled_write_bytes: 0
+	for i := 0; i < sl; i++ {
+		// Start of line. So next bytes are the key.
+		for k := ioKind(0); k < ioKindNb; k++ {
+			if fastHasPrefix(s, i, ioKindKeys[k]) {
+				ps.ioc[k], i = fastParseNextUint64(s, i+len(ioKindKeys[k]))
+				break
 			}
-			sol = true
 		}
+		i = findNextIndex(s, i, '\n')
 	}
-	return
+	// use these 2 metrics rather that rchar/wchar to track disk level activity (avoid counting memory cache accesses)
+	ps.io = ps.ioc[ioRead] + ps.ioc[ioWrite]
 }
@@ -1,8 +1,10 @@
 package procfilter
 
 import (
+	"fmt"
 	"regexp"
 	"strconv"
+	"strings"
 
 	"github.com/influxdata/telegraf"
 )
@@ -50,7 +52,18 @@ func (m *measurement) getTags(s stat, prefix string) (map[string]string, error)
 	return tags, nil
 }
 
+// userVar returns the value of a user variable named like a builtin that is not reserved ("" if there is none).
+func userVar(s stat, name string) string {
+	if _, reserved := reservedVarNames[name]; reserved {
+		return ""
+	}
+	return s.Var(name)
+}
+
 func tagNameToValue(s stat, name string) (string, error) {
+	if v := userVar(s, name); v != "" {
+		return v, nil
+	}
 	switch name {
 	case "user":
 		return s.User()
@@ -64,8 +77,60 @@ func tagNameToValue(s stat, name string) (string, error) {
 		return v, nil
 	case "exe":
 		return s.Exe()
+	case "cwd":
+		return s.Cwd()
+	case "root":
+		return s.Root()
+	case "cgroup":
+		return s.Cgroup()
+	case "unit":
+		return s.Unit()
+	case "slice":
+		return s.Slice()
+	case "container_id", "container":
+		return s.ContainerID()
+	case "container_name":
+		return s.ContainerName()
+	case "container_image":
+		return s.ContainerImage()
+	case "container_runtime":
+		return s.ContainerRuntime()
+	case "pidns", "netns", "mntns", "userns", "utsns":
+		v, err := s.NS(nsKindNames[name])
+		if v == 0 {
+			return "", err
+		}
+		return strconv.FormatUint(v, 10), err
+	case "ns_pid":
+		v := s.NSPid()
+		if v == 0 {
+			return "", nil
+		}
+		return strconv.Itoa(int(v)), nil
+	case "hostname":
+		return s.Hostname()
+	case "state":
+		return s.State()
+	case "sched_policy":
+		return s.SchedPolicy()
+	case "processor":
+		v, err := s.Processor()
+		if v < 0 {
+			return "", err
+		}
+		return strconv.Itoa(int(v)), err
+	case "cpus_allowed":
+		return s.CpusAllowed()
 	case "pid":
-		return strconv.Itoa(int(s.PID())), nil
+		return strconv.Itoa(int(s.OutputPID())), nil
+	case "tid":
+		v := s.TID()
+		if v == 0 {
+			return "", nil
+		}
+		return strconv.Itoa(int(v)), nil
+	case "thread_name":
+		return s.ThreadName()
 	case "uid":
 		v, err := s.UID()
 		return strconv.Itoa(int(v)), err
@@ -73,6 +138,13 @@ func tagNameToValue(s stat, name string) (string, error) {
 		v, err := s.GID()
 		return strconv.Itoa(int(v)), err
 	default:
+		if isKindName(name) {
+			v, err := kindField(s, name)
+			if err != nil || v == nil {
+				return "", err
+			}
+			return fmt.Sprint(v), nil
+		}
 		return s.Var(name), nil
 	}
 }
@@ -86,6 +158,10 @@ func (m *measurement) getFields(s stat, prefix string) (map[string]interface{},
 		} else {
 			prefField = prefix + field
 		}
+		if v := userVar(s, field); v != "" {
+			fields[prefField] = v
+			continue
+		}
 		switch field {
 		case "user":
 			v, err := s.User()
@@ -124,7 +200,7 @@ func (m *measurement) getFields(s stat, prefix string) (map[string]interface{},
 			}
 			fields[prefField] = v
 		case "pid":
-			pid := int64(s.PID())
+			pid := int64(s.OutputPID())
 			if pid >= 0 { // Do not output internal  pack"  pseudo PIDs.
 				fields[prefField] = pid
 			}
@@ -203,7 +279,140 @@ func (m *measurement) getFields(s stat, prefix string) (map[string]interface{},
 		case "process_nb":
 			v := s.ProcessNumber()
 			fields[prefField] = v
+		case "state":
+			v, _ := s.State()
+			if v == "" {
+				continue
+			}
+			fields[prefField] = v
+		case "running_nb":
+			fields[prefField] = s.StateNumber("R")
+		case "sleeping_nb":
+			fields[prefField] = s.StateNumber("S")
+		case "blocked_nb":
+			fields[prefField] = s.StateNumber("D")
+		case "zombie_nb":
+			fields[prefField] = s.StateNumber("Z")
+		case "stopped_nb":
+			fields[prefField] = s.StateNumber("Tt")
+		case "sched_policy":
+			v, _ := s.SchedPolicy()
+			if v == "" {
+				continue
+			}
+			fields[prefField] = v
+		case "processor":
+			v, err := s.Processor()
+			if err != nil || v < 0 {
+				continue
+			}
+			fields[prefField] = v
+		case "cpus_allowed":
+			v, _ := s.CpusAllowed()
+			if v == "" {
+				continue
+			}
+			fields[prefField] = v
+		case "start_time":
+			v, err := s.StartTime()
+			if err != nil || v == 0 {
+				continue
+			}
+			fields[prefField] = v / 1e9
+		case "listen_ports":
+			v, _ := s.ListenPorts()
+			if len(v) == 0 {
+				continue
+			}
+			ports := make([]string, len(v))
+			for i, lp := range v {
+				ports[i] = lp.String()
+			}
+			fields[prefField] = strings.Join(ports, ",")
+		case "tid":
+			v := s.TID()
+			if v == 0 {
+				continue
+			}
+			fields[prefField] = int64(v)
+		case "thread_name":
+			v, _ := s.ThreadName()
+			if v == "" {
+				continue
+			}
+			fields[prefField] = v
+		case "cwd":
+			v, _ := s.Cwd()
+			if v == "" {
+				continue
+			}
+			fields[prefField] = v
+		case "root":
+			v, _ := s.Root()
+			if v == "" {
+				continue
+			}
+			fields[prefField] = v
+		case "cgroup":
+			v, _ := s.Cgroup()
+			if v == "" {
+				continue
+			}
+			fields[prefField] = v
+		case "unit":
+			v, _ := s.Unit()
+			if v == "" {
+				continue
+			}
+			fields[prefField] = v
+		case "slice":
+			v, _ := s.Slice()
+			if v == "" {
+				continue
+			}
+			fields[prefField] = v
+		case "container_id", "container_name", "container_image", "container_runtime":
+			v, _ := tagNameToValue(s, field)
+			if v == "" {
+				continue
+			}
+			fields[prefField] = v
+		case "pidns", "netns", "mntns", "userns", "utsns":
+			v, _ := s.NS(nsKindNames[field])
+			if v == 0 {
+				continue
+			}
+			fields[prefField] = int64(v)
+		case "ns_pid":
+			v := s.NSPid()
+			if v == 0 {
+				continue
+			}
+			fields[prefField] = int64(v)
+		case "hostname":
+			v, _ := s.Hostname()
+			if v == "" {
+				continue
+			}
+			fields[prefField] = v
 		default:
+			if strings.HasPrefix(field, "mem_node") {
+				// eg: mem_node1
+				n, err := strconv.Atoi(field[8:])
+				if err == nil {
+					v, _ := s.MemNode(n)
+					fields[prefField] = v
+					continue
+				}
+			}
+			if isKindName(field) {
+				v, err := kindField(s, field)
+				if err != nil || v == nil {
+					continue
+				}
+				fields[prefField] = v
+				continue
+			}
 			v := s.Var(field)
 			if v != "" {
 				fields[prefField] = v
@@ -213,7 +422,7 @@ func (m *measurement) getFields(s stat, prefix string) (map[string]interface{},
 	return fields, nil
 }
 
-//var reMeasurementSubstitution = regexp.MustCompile("${[^}]*}|$[a-zA-Z0-9_]*")
+// var reMeasurementSubstitution = regexp.MustCompile("${[^}]*}|$[a-zA-Z0-9_]*")
 var reMeasurementSubstitution = regexp.MustCompile("[$][a-zA-Z0-9_]+")
 
 // Push the current tag/fields of a measuremnt to a telegraf accumulator.
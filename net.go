@@ -0,0 +1,374 @@
+package procfilter
+
+/* Network related metrics.
+The socket tables (/proc/[pid]/net/tcp, udp, ...) are per network namespace. They are read at most once per sample and namespace and shared by all the processes of this namespace.
+*/
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// TCP connection states. The st column of /proc/net/tcp is this value plus one.
+type tcpState int
+
+const (
+	tcpEstablished tcpState = iota
+	tcpSynSent
+	tcpSynRecv
+	tcpFinWait1
+	tcpFinWait2
+	tcpTimeWait
+	tcpClose
+	tcpCloseWait
+	tcpLastAck
+	tcpListen
+	tcpClosing
+	tcpStateNb // Number of tcpState values.
+)
+
+// Criteria/field names for the number of TCP connections in a given state.
+var tcpStateNames = map[string]tcpState{
+	"tcp_established": tcpEstablished,
+	"tcp_syn_sent":    tcpSynSent,
+	"tcp_syn_recv":    tcpSynRecv,
+	"tcp_fin_wait1":   tcpFinWait1,
+	"tcp_fin_wait2":   tcpFinWait2,
+	"tcp_time_wait":   tcpTimeWait,
+	"tcp_close":       tcpClose,
+	"tcp_close_wait":  tcpCloseWait,
+	"tcp_last_ack":    tcpLastAck,
+	"tcp_listen":      tcpListen,
+	"tcp_closing":     tcpClosing,
+}
+
+// A socket found in /proc/[pid]/net/tcp, tcp6, udp or udp6.
+type sockInfo struct {
+	state  uint8  // st column (see tcpState).
+	port   uint16 // local port.
+	udp    bool
+	listen bool // listening TCP socket or unconnected UDP socket bound to a non ephemeral port.
+}
+
+// A port a process is listening on.
+type listenPort struct {
+	port uint16
+	udp  bool
+}
+
+func (lp listenPort) String() string {
+	if lp.udp {
+		return fmt.Sprintf("%d/udp", lp.port)
+	}
+	return strconv.Itoa(int(lp.port))
+}
+
+// addListenPort inserts a port in a sorted list of unique ports (TCP first, then UDP). (eg: a port listening on both IPv4 and IPv6 is listed once)
+func addListenPort(lps []listenPort, lp listenPort) []listenPort {
+	i := 0
+	for ; i < len(lps); i++ {
+		if lps[i] == lp {
+			return lps // Already known.
+		}
+		if lp.udp == lps[i].udp && lp.port < lps[i].port || !lp.udp && lps[i].udp {
+			break // lp goes before lps[i].
+		}
+	}
+	lps = append(lps, listenPort{})
+	copy(lps[i+1:], lps[i:])
+	lps[i] = lp
+	return lps
+}
+
+// The sockets of a network namespace.
+type sockTab struct {
+	socks    map[uint64]sockInfo // Indexed by socket inode.
+	timeWait uint32              // Number of TCP connections in the TIME_WAIT state (they are not owned by a process anymore, their inode is 0).
+}
+
+// Socket tables of the network namespaces (indexed by namespace inode). Rebuilt every sample.
+var sockTables = map[uint64]*sockTab{}
+var sockTablesTs tStamp
+
+// sockTable returns the sockets of the network namespace of a process (nil if the namespace is unknown, eg: the /proc/[pid]/ns/net link of a process of another user cannot be read, nor its fds).
+func sockTable(ps *procStat) *sockTab {
+	if sockTablesTs != stamp {
+		sockTables = map[uint64]*sockTab{}
+		sockTablesTs = stamp
+	}
+	ns := ps.netNS()
+	if ns == 0 {
+		return nil
+	}
+	if t, known := sockTables[ns]; known {
+		return t
+	}
+	readEphemeralPorts() // Before fastReadOpen, fastRead would clobber the shared buffer of fastReadLine.
+	t := &sockTab{socks: map[uint64]sockInfo{}}
+	t.read(procFileName(ps.pid, "net/tcp"), false)
+	t.read(procFileName(ps.pid, "net/tcp6"), false)
+	t.read(procFileName(ps.pid, "net/udp"), true)
+	t.read(procFileName(ps.pid, "net/udp6"), true)
+	sockTables[ns] = t
+	return t
+}
+
+// The range of the ephemeral ports (used for the unbound or connect()-ed sockets).
+var ephemeralLow, ephemeralHigh uint64
+var ephemeralRead bool
+
+// readEphemeralPorts reads the range the kernel uses for the implicitly bound sockets (/proc/sys/net/ipv4/ip_local_port_range), once.
+func readEphemeralPorts() {
+	if ephemeralRead {
+		return
+	}
+	ephemeralRead = true
+	ephemeralLow, ephemeralHigh = 32768, 60999 // Linux default.
+	s, err := fastRead("/proc/sys/net/ipv4/ip_local_port_range")
+	if err == nil {
+		// eg: 32768	60999
+		var i int
+		ephemeralLow, i = fastParseNextUint64(s, 0)
+		ephemeralHigh, _ = fastParseNextUint64(s, i)
+	}
+}
+
+// isEphemeralPort checks if a port is in the ephemeral range (see readEphemeralPorts).
+func isEphemeralPort(port uint16) bool {
+	return ephemeralLow <= uint64(port) && uint64(port) <= ephemeralHigh
+}
+
+// read adds the sockets listed in one of the /proc/[pid]/net/{tcp,tcp6,udp,udp6} files.
+func (t *sockTab) read(fn string, udp bool) {
+	err := fastReadOpen(fn)
+	if err != nil {
+		return // eg: kernel without IPv6
+	}
+	fastReadLine() // Skip the header line.
+	for {
+		l := fastReadLine()
+		if l == nil {
+			break
+		}
+		si, ino := parseSockLine(l, udp)
+		if ino == 0 {
+			if !udp && si.state == uint8(tcpTimeWait)+1 {
+				t.timeWait++ // Not owned by a process anymore, counted for the namespace.
+			}
+			continue
+		}
+		t.socks[ino] = si
+	}
+}
+
+// parseSockLine parses a socket line of a /proc/[pid]/net/{tcp,tcp6,udp,udp6} file and returns the socket and its inode.
+func parseSockLine(l []byte, udp bool) (si sockInfo, ino uint64) {
+	//  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+	//   0: 0100007F:0CEA 00000000:0000 0A 00000000:00000000 00:00000000 00000000  1000        0 21586 1 0000000000000000 100 0 0 10 0
+	var f int // field number
+	var v, remPort uint64
+	ll := len(l)
+	for i := 0; i < ll && f <= 9; i++ {
+		if l[i] == ' ' {
+			continue
+		}
+		// Start of a field.
+		switch f {
+		case 1: // local_address (IPv4 or IPv6) and port
+			i = findNextIndex(l, i, ':')
+			v, i = fastParseHex(l, i+1)
+			si.port = uint16(v)
+		case 2: // rem_address and port
+			i = findNextIndex(l, i, ':')
+			remPort, i = fastParseHex(l, i+1)
+		case 3: // st
+			v, i = fastParseHex(l, i)
+			si.state = uint8(v)
+		case 9: // inode
+			ino, i = fastParseUint64(l, i)
+		default: // Skip this field.
+			i = findNextIndex(l, i, ' ')
+		}
+		f++
+	}
+	si.udp = udp
+	si.listen = parseSockListen(si, remPort)
+	return si, ino
+}
+
+// parseSockListen tells if a socket is a listening one: a TCP socket in the LISTEN state or an unconnected UDP socket bound to a non ephemeral port (an unconnected UDP client socket, eg: a resolver or a syslog client using sendto, gets an ephemeral port).
+func parseSockListen(si sockInfo, remPort uint64) bool {
+	if !si.udp {
+		return si.state == uint8(tcpListen)+1
+	}
+	return remPort == 0 && si.port != 0 && !isEphemeralPort(si.port)
+}
+
+// fastParseHex parses an hexadecimal integer (uppercase digits as found in /proc/net/tcp).
+func fastParseHex(s []byte, i int) (res uint64, index int) {
+	sl := len(s)
+	for ; i < sl; i++ {
+		c := s[i]
+		switch {
+		case '0' <= c && c <= '9':
+			res = res<<4 + uint64(c-'0')
+		case 'A' <= c && c <= 'F':
+			res = res<<4 + uint64(c-'A'+10)
+		case 'a' <= c && c <= 'f':
+			res = res<<4 + uint64(c-'a'+10)
+		default:
+			return res, i
+		}
+	}
+	return res, i
+}
+
+// Network traffic counters of a network namespace.
+type netKind int
+
+const (
+	netRxBytes   netKind = iota // bytes received
+	netRxPackets                // packets received
+	netTxBytes                  // bytes transmitted
+	netTxPackets                // packets transmitted
+	netKindNb                   // Number of netKind values.
+)
+
+// Criteria/field names for the netKind rates (per second).
+var netRateNames = map[string]netKind{
+	"rx_bps": netRxBytes,
+	"rx_pps": netRxPackets,
+	"tx_bps": netTxBytes,
+	"tx_pps": netTxPackets,
+}
+
+// Traffic of a network namespace as found in /proc/[pid]/net/dev.
+type netTraffic struct {
+	ts       tStamp
+	time     time.Time
+	prevTime time.Time
+	cnt      [netKindNb]uint64
+	prevCnt  [netKindNb]uint64
+	rates    [netKindNb]uint64
+}
+
+// Traffic of the network namespaces (indexed by namespace inode). Namespaces not seen during the last sample are forgotten.
+var netTraffics = map[uint64]*netTraffic{}
+var netTrafficsTs tStamp
+
+// nsTraffic returns the up to date traffic of the network namespace ns read using the pid of one of its processes.
+func nsTraffic(ns uint64, pid tPid) *netTraffic {
+	if netTrafficsTs != stamp {
+		for k, nt := range netTraffics {
+			if nt.ts != netTrafficsTs {
+				delete(netTraffics, k)
+			}
+		}
+		netTrafficsTs = stamp
+	}
+	nt, known := netTraffics[ns]
+	if !known {
+		nt = &netTraffic{}
+		netTraffics[ns] = nt
+	}
+	if nt.ts == stamp {
+		return nt
+	}
+	nt.ts = stamp
+	nt.prevCnt = nt.cnt
+	nt.prevTime = nt.time
+	if !nt.readNetDev(procFileName(pid, "net/dev")) {
+		nt.time = time.Time{}
+		nt.rates = [netKindNb]uint64{}
+		return nt
+	}
+	nt.time = time.Now()
+	if nt.prevTime.IsZero() {
+		return nt // First sample, we need 2 samples to compute rates.
+	}
+	dt := nt.time.Sub(nt.prevTime).Seconds()
+	for k := range nt.cnt {
+		nt.rates[k] = 0
+		if nt.cnt[k] > nt.prevCnt[k] {
+			nt.rates[k] = uint64(float64(nt.cnt[k]-nt.prevCnt[k]) / dt)
+		}
+	}
+	return nt
+}
+
+// readNetDev sums the counters of all the interfaces (except the loopback) listed in a /proc/[pid]/net/dev file.
+func (nt *netTraffic) readNetDev(fn string) bool {
+	err := fastReadOpen(fn)
+	if err != nil {
+		return false
+	}
+	nt.cnt = [netKindNb]uint64{}
+	fastReadLine() // Skip the 2 header lines.
+	fastReadLine()
+	for {
+		l := fastReadLine()
+		if l == nil {
+			break
+		}
+		//   eth0: 1882  27  0  0  0  0  0  0  2106  24  0  0  0  0  0  0
+		i := 0
+		for ; i < len(l) && l[i] == ' '; i++ {
+		}
+		if fastHasPrefix(l, i, "lo:") {
+			continue
+		}
+		i = findNextIndex(l, i, ':')
+		var v uint64
+		for f := 0; f < 10; f++ {
+			v, i = fastParseNextUint64(l, i)
+			switch f {
+			case 0:
+				nt.cnt[netRxBytes] += v
+			case 1:
+				nt.cnt[netRxPackets] += v
+			case 8:
+				nt.cnt[netTxBytes] += v
+			case 9:
+				nt.cnt[netTxPackets] += v
+			}
+		}
+	}
+	return true
+}
+
+// NetRate is the traffic rate of the network namespace of the process. It is 0 for the processes sharing the procfilter (usually the host) namespace.
+func (ps *procStat) NetRate(k netKind) (uint64, error) {
+	if ps.status == DEAD || ps.IsThread() {
+		return 0, nil
+	}
+	ns := ps.netNS()
+	if ns == 0 || ns == getSelfNS(nsNet) {
+		return 0, nil
+	}
+	return nsTraffic(ns, ps.pid).rates[k], nil
+}
+
+// NetRate is the sum of the traffic rates of the distinct network namespaces of the pack elements.
+func (p *packStat) NetRate(k netKind) (uint64, error) {
+	if p.netRateTs[k] == stamp {
+		return p.netRate[k], nil
+	}
+	var sum uint64
+	seen := map[uint64]bool{}
+	self := getSelfNS(nsNet)
+	for _, s := range p.elems {
+		if s.status == DEAD || s.IsThread() {
+			continue
+		}
+		ns := s.netNS()
+		if ns == 0 || ns == self || seen[ns] {
+			continue
+		}
+		seen[ns] = true
+		sum += nsTraffic(ns, s.pid).rates[k]
+	}
+	p.netRate[k] = sum
+	p.netRateTs[k] = stamp
+	return sum, nil
+}
@@ -0,0 +1,158 @@
+package procfilter
+
+/* Linux namespaces.
+The namespaces of a process are identified by the inodes of the /proc/[pid]/ns/* links. Processes of a container share the namespaces of the container, processes of the host share the procfilter ones (unless procfilter itself runs in a container).
+*/
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// Namespace types.
+type nsKind int
+
+const (
+	nsPid    nsKind = iota // PID namespace
+	nsNet                  // network namespace
+	nsMnt                  // mount namespace
+	nsUser                 // user namespace
+	nsUts                  // hostname namespace
+	nsKindNb               // Number of nsKind values.
+)
+
+// Tag/field names of the namespace inodes (also the kinds accepted by the namespace filter).
+var nsKindNames = map[string]nsKind{
+	"pidns":  nsPid,
+	"netns":  nsNet,
+	"mntns":  nsMnt,
+	"userns": nsUser,
+	"utsns":  nsUts,
+}
+
+// Links names in /proc/[pid]/ns/ (indexed by nsKind).
+var nsFileNames = [nsKindNb]string{"pid", "net", "mnt", "user", "uts"}
+
+// readNSLink returns the inode of a namespace link (0 if unknown).
+// eg: net:[4026531840]
+func readNSLink(fn string) uint64 {
+	target, err := os.Readlink(fn)
+	if err != nil {
+		return 0 // eg: process of another user when procfilter is not root.
+	}
+	ino, _ := fastParseNextUint64([]byte(target), 0)
+	return ino
+}
+
+// updateFromNS reads the namespaces of the process. They are read once, processes seldom change their namespaces.
+func (ps *procStat) updateFromNS() {
+	if ps.nsRead {
+		return
+	}
+	ps.nsRead = true
+	for k, fn := range nsFileNames {
+		ps.ns[k] = readNSLink(procFileName(ps.pid, "ns/"+fn))
+	}
+}
+
+// NS is the inode of a namespace of the process (0 if unknown).
+func (ps *procStat) NS(k nsKind) (uint64, error) {
+	ps.updateFromNS()
+	return ps.ns[k], nil
+}
+
+// netNS returns the inode of the network namespace of the process (0 if unknown).
+func (ps *procStat) netNS() uint64 {
+	ps.updateFromNS()
+	return ps.ns[nsNet]
+}
+
+// NSPid is the PID of the process as seen in its own PID namespace (the last value of the NSpid line of /proc/[pid]/status, 0 if unknown).
+func (ps *procStat) NSPid() tPid {
+	if ps.statusTs == 0 { // Read only once, it does not change.
+		ps.updateFromStatus()
+	}
+	return ps.nsPid
+}
+
+// The namespaces of the procfilter process (indexed by nsKind).
+var selfNS [nsKindNb]uint64
+var selfNSRead bool
+
+// getSelfNS returns the inode of a namespace of the procfilter process.
+func getSelfNS(k nsKind) uint64 {
+	if !selfNSRead {
+		selfNSRead = true
+		for k, fn := range nsFileNames {
+			selfNS[k] = readNSLink("/proc/self/ns/" + fn)
+		}
+	}
+	return selfNS[k]
+}
+
+// Hostname of an uts namespace.
+type utsHostname struct {
+	ts       tStamp // Last sample this namespace was seen.
+	hostname string
+}
+
+// Hostnames of the uts namespaces (indexed by namespace inode). Namespaces not seen during the last sample are forgotten.
+var utsHostnames = map[uint64]*utsHostname{}
+var utsHostnamesTs tStamp
+
+// Hostname is the hostname of the uts namespace of the process. For the procfilter namespace this is the host name, for other namespaces it is read from the /etc/hostname file found in the root directory of the process (the hostname of a container is not readable without entering its namespace, but the runtimes keep this file up to date).
+func (ps *procStat) Hostname() (string, error) {
+	uts, _ := ps.NS(nsUts)
+	if uts == 0 {
+		return "", nil
+	}
+	if utsHostnamesTs != stamp {
+		for k, uh := range utsHostnames {
+			if uh.ts != utsHostnamesTs {
+				delete(utsHostnames, k)
+			}
+		}
+		utsHostnamesTs = stamp
+	}
+	uh, known := utsHostnames[uts]
+	if !known {
+		uh = &utsHostname{}
+		if uts == getSelfNS(nsUts) {
+			uh.hostname, _ = os.Hostname()
+		} else {
+			b, err := ioutil.ReadFile(procFileName(ps.pid, "root/etc/hostname"))
+			if err == nil {
+				uh.hostname = strings.TrimSpace(string(b))
+			}
+		}
+		utsHostnames[uts] = uh
+	}
+	uh.ts = stamp
+	return uh.hostname, nil
+}
+
+// NS is the namespace shared by all the pack elements (0 if they differ or if one of them is unknown, whatever the elements order).
+func (p *packStat) NS(k nsKind) (uint64, error) {
+	var ns uint64
+	for i, s := range p.elems {
+		v, _ := s.NS(k)
+		if v == 0 || i > 0 && v != ns {
+			return 0, nil
+		}
+		ns = v
+	}
+	return ns, nil
+}
+
+// NSPid is the namespace PID of a single process pack (0 otherwise).
+func (p *packStat) NSPid() tPid {
+	if len(p.elems) != 1 {
+		return 0
+	}
+	return p.elems[0].NSPid()
+}
+
+func (p *packStat) Hostname() (string, error) {
+	return p.common((*procStat).Hostname), nil
+}
@@ -45,18 +45,63 @@ type packStat struct {
 	threadNb   uint64
 	fdNbTs     tStamp
 	fdNb       uint64
+	numaTs     tStamp
+	numa       []uint64
+	limitsTs   tStamp
+	fdLimit    uint64
+	fdPct      float32
+	nprocLimit uint64
+	nprocPct   float32
 	cpuTs      tStamp
 	cpu        float32
+	cpuUserTs  tStamp
+	cpuUser    float32
+	cpuSysTs   tStamp
+	cpuSys     float32
+	cpuWaitTs  tStamp
+	cpuWait    float32
+	ioWaitTs   tStamp
+	ioWait     float32
 	ioTs       tStamp
 	io         uint64
 	iobpsTs    tStamp
 	iobps      uint64
+	minFltTs   tStamp
+	minFlt     float32
+	majFltTs   tStamp
+	majFlt     float32
+	volCtxTs   tStamp
+	volCtx     float32
+	nvolCtxTs  tStamp
+	nvolCtx    float32
 	rssTs      tStamp
 	rss        uint64
 	vszTs      tStamp
 	vsz        uint64
 	swapTs     tStamp
 	swap       uint64
+	oomTs      tStamp
+	oomScore   uint64
+	oomAdj     int64
+	memTs      [memKindNb]tStamp
+	mem        [memKindNb]uint64
+	ioStatTs   [ioKindNb]tStamp
+	ioStat     [ioKindNb]uint64
+	ioRateTs   [ioKindNb]tStamp
+	ioRate     [ioKindNb]uint64
+	fdTypeTs   [fdKindNb]tStamp
+	fdType     [fdKindNb]uint64
+	deletedTs  tStamp
+	deletedNb  uint64
+	deletedSz  uint64
+	tcpStateTs [tcpStateNb]tStamp
+	tcpState   [tcpStateNb]uint64
+	nsTWaitTs  tStamp
+	nsTWait    uint64
+	listenTs   tStamp
+	listen     []listenPort
+	netRateTs  [netKindNb]tStamp
+	netRate    [netKindNb]uint64
 	vars       map[string]string
 }
 
@@ -64,6 +109,10 @@ func (p *packStat) PID() tPid {
 	return p.pid
 }
 
+func (p *packStat) OutputPID() tPid {
+	return p.pid
+}
+
 func (p *packStat) ProcessNumber() uint64 {
 	if p.procNbTs == stamp {
 		return p.procNb
@@ -77,6 +126,177 @@ func (p *packStat) ProcessNumber() uint64 {
 	return nb
 }
 
+// State returns the state shared by all processes of the pack or "" if they are in various states (or one is unknown).
+func (p *packStat) State() (string, error) {
+	if p.other != "" {
+		return p.other, nil
+	}
+	return p.common((*procStat).State), nil
+}
+
+// StateNumber counts the processes in one of the states (eg: "Tt").
+func (p *packStat) StateNumber(states string) uint64 {
+	var nb uint64
+	for _, s := range p.elems {
+		nb += s.StateNumber(states)
+	}
+	return nb
+}
+
+// Priority is the best (lowest) priority of the pack elements.
+func (p *packStat) Priority() (int64, error) {
+	var prio int64
+	for i, s := range p.elems {
+		v, _ := s.Priority()
+		if i == 0 || v < prio {
+			prio = v
+		}
+	}
+	return prio, nil
+}
+
+// Nice is the most favorable (lowest) nice level of the pack elements.
+func (p *packStat) Nice() (int64, error) {
+	var nice int64
+	for i, s := range p.elems {
+		v, _ := s.Nice()
+		if i == 0 || v < nice {
+			nice = v
+		}
+	}
+	return nice, nil
+}
+
+// RTPriority is the highest realtime priority of the pack elements.
+func (p *packStat) RTPriority() (uint64, error) {
+	var prio uint64
+	for _, s := range p.elems {
+		v, _ := s.RTPriority()
+		prio = maxUint64(prio, v)
+	}
+	return prio, nil
+}
+
+// SchedPolicy is the scheduling policy common to all the pack elements (or "").
+func (p *packStat) SchedPolicy() (string, error) {
+	return p.common((*procStat).SchedPolicy), nil
+}
+
+// StartTime is the start time of the oldest pack element.
+func (p *packStat) StartTime() (uint64, error) {
+	var st uint64
+	for _, s := range p.elems {
+		v, _ := s.StartTime()
+		if v != 0 && (st == 0 || v < st) {
+			st = v
+		}
+	}
+	return st, nil
+}
+
+// Age is the age of the oldest pack element.
+func (p *packStat) Age() (uint64, error) {
+	var age uint64
+	for _, s := range p.elems {
+		v, _ := s.Age()
+		age = maxUint64(age, v)
+	}
+	return age, nil
+}
+
+// YoungestAge is the age of the youngest pack element.
+func (p *packStat) YoungestAge() (uint64, error) {
+	var age uint64
+	for i, s := range p.elems {
+		v, _ := s.Age()
+		if i == 0 || v < age {
+			age = v
+		}
+	}
+	return age, nil
+}
+
+// fillNuma sums the memory pages per NUMA node of the pack elements.
+func (p *packStat) fillNuma() {
+	if p.numaTs == stamp {
+		return
+	}
+	p.numa = p.numa[:0]
+	for _, s := range p.elems {
+		s.MemNode(0) // Refresh the process numa values (if needed).
+		for n, v := range s.numa {
+			if n >= len(p.numa) {
+				p.numa = append(p.numa, 0)
+			}
+			p.numa[n] += v
+		}
+	}
+	p.numaTs = stamp
+}
+
+func (p *packStat) MemNode(node int) (uint64, error) {
+	p.fillNuma()
+	if node >= len(p.numa) {
+		return 0, nil
+	}
+	return p.numa[node], nil
+}
+
+func (p *packStat) NumaImbalance() (float32, error) {
+	p.fillNuma()
+	return numaImbalance(p.numa), nil
+}
+
+// Processor is the CPU common to all the pack elements (-1 if they ran on different CPUs).
+func (p *packStat) Processor() (int64, error) {
+	var cpu int64 = -1
+	for i, s := range p.elems {
+		v, _ := s.Processor()
+		if i == 0 {
+			cpu = v
+		} else if v != cpu {
+			return -1, nil
+		}
+	}
+	return cpu, nil
+}
+
+// CpusAllowed is the list of allowed CPUs common to all the pack elements (or "").
+func (p *packStat) CpusAllowed() (string, error) {
+	return p.common((*procStat).CpusAllowed), nil
+}
+
+// Cwd is the working directory shared by all the pack elements ("" if they differ).
+func (p *packStat) Cwd() (string, error) {
+	return p.common((*procStat).Cwd), nil
+}
+
+// Root is the root directory shared by all the pack elements ("" if they differ).
+func (p *packStat) Root() (string, error) {
+	return p.common((*procStat).Root), nil
+}
+
+// common returns the value shared by all the pack elements ("" if they differ or if one of them is unknown, whatever the elements order).
+func (p *packStat) common(get func(*procStat) (string, error)) string {
+	var v string
+	for i, s := range p.elems {
+		sv, _ := get(s)
+		if sv == "" || i > 0 && sv != v {
+			return ""
+		}
+		v = sv
+	}
+	return v
+}
+
+func (p *packStat) TID() tPid {
+	return 0
+}
+
+func (p *packStat) ThreadName() (string, error) {
+	return "", nil
+}
+
 func (p *packStat) Args() ([]string, error) {
 	return []string{}, nil
 }
@@ -109,6 +329,71 @@ func (p *packStat) CPU() (float32, error) {
 	return p.cpu, nil
 }
 
+func (p *packStat) CPUUser() (float32, error) {
+	if p.cpuUserTs == stamp {
+		return p.cpuUser, nil
+	}
+	var cpu float64
+	for _, s := range p.elems {
+		c, _ := s.CPUUser()
+		cpu += float64(c)
+	}
+	// Same quick fix as CPU().
+	if cpu > 100 {
+		cpu = 100
+	}
+	p.cpuUser = float32(cpu)
+	p.cpuUserTs = stamp
+	return p.cpuUser, nil
+}
+
+func (p *packStat) CPUSystem() (float32, error) {
+	if p.cpuSysTs == stamp {
+		return p.cpuSys, nil
+	}
+	var cpu float64
+	for _, s := range p.elems {
+		c, _ := s.CPUSystem()
+		cpu += float64(c)
+	}
+	// Same quick fix as CPU().
+	if cpu > 100 {
+		cpu = 100
+	}
+	p.cpuSys = float32(cpu)
+	p.cpuSysTs = stamp
+	return p.cpuSys, nil
+}
+
+// CPUWait sums the run queue wait percents. (Can be more than 100% as several processes may wait at the same time)
+func (p *packStat) CPUWait() (float32, error) {
+	if p.cpuWaitTs == stamp {
+		return p.cpuWait, nil
+	}
+	var sum float64
+	for _, s := range p.elems {
+		v, _ := s.CPUWait()
+		sum += float64(v)
+	}
+	p.cpuWait = float32(sum)
+	p.cpuWaitTs = stamp
+	return p.cpuWait, nil
+}
+
+func (p *packStat) IOWait() (float32, error) {
+	if p.ioWaitTs == stamp {
+		return p.ioWait, nil
+	}
+	var sum float64
+	for _, s := range p.elems {
+		v, _ := s.IOWait()
+		sum += float64(v)
+	}
+	p.ioWait = float32(sum)
+	p.ioWaitTs = stamp
+	return p.ioWait, nil
+}
+
 func (p *packStat) IO() (uint64, error) {
 	if p.ioTs == stamp {
 		return p.io, nil
@@ -137,6 +422,62 @@ func (p *packStat) IObps() (uint64, error) {
 	return p.iobps, nil
 }
 
+func (p *packStat) MinFlt() (float32, error) {
+	if p.minFltTs == stamp {
+		return p.minFlt, nil
+	}
+	var sum float64
+	for _, s := range p.elems {
+		v, _ := s.MinFlt()
+		sum += float64(v)
+	}
+	p.minFlt = float32(sum)
+	p.minFltTs = stamp
+	return p.minFlt, nil
+}
+
+func (p *packStat) MajFlt() (float32, error) {
+	if p.majFltTs == stamp {
+		return p.majFlt, nil
+	}
+	var sum float64
+	for _, s := range p.elems {
+		v, _ := s.MajFlt()
+		sum += float64(v)
+	}
+	p.majFlt = float32(sum)
+	p.majFltTs = stamp
+	return p.majFlt, nil
+}
+
+func (p *packStat) VolCtxSw() (float32, error) {
+	if p.volCtxTs == stamp {
+		return p.volCtx, nil
+	}
+	var sum float64
+	for _, s := range p.elems {
+		v, _ := s.VolCtxSw()
+		sum += float64(v)
+	}
+	p.volCtx = float32(sum)
+	p.volCtxTs = stamp
+	return p.volCtx, nil
+}
+
+func (p *packStat) NvolCtxSw() (float32, error) {
+	if p.nvolCtxTs == stamp {
+		return p.nvolCtx, nil
+	}
+	var sum float64
+	for _, s := range p.elems {
+		v, _ := s.NvolCtxSw()
+		sum += float64(v)
+	}
+	p.nvolCtx = float32(sum)
+	p.nvolCtxTs = stamp
+	return p.nvolCtx, nil
+}
+
 func (p *packStat) GID() (int32, error) {
 	if p.gid == -2 {
 		return -2, nil // other
@@ -248,6 +589,76 @@ func (p *packStat) Swap() (uint64, error) {
 	return sum, nil
 }
 
+func (p *packStat) Mem(k memKind) (uint64, error) {
+	if p.memTs[k] == stamp {
+		return p.mem[k], nil
+	}
+	var sum uint64
+	for _, s := range p.elems {
+		v, _ := s.Mem(k)
+		sum += v
+	}
+	p.mem[k] = sum
+	p.memTs[k] = stamp
+	return sum, nil
+}
+
+func (p *packStat) IOStat(k ioKind) (uint64, error) {
+	if p.ioStatTs[k] == stamp {
+		return p.ioStat[k], nil
+	}
+	var sum uint64
+	for _, s := range p.elems {
+		v, _ := s.IOStat(k)
+		sum += v
+	}
+	p.ioStat[k] = sum
+	p.ioStatTs[k] = stamp
+	return sum, nil
+}
+
+func (p *packStat) IOStatRate(k ioKind) (uint64, error) {
+	if p.ioRateTs[k] == stamp {
+		return p.ioRate[k], nil
+	}
+	var sum uint64
+	for _, s := range p.elems {
+		v, _ := s.IOStatRate(k)
+		sum += v
+	}
+	p.ioRate[k] = sum
+	p.ioRateTs[k] = stamp
+	return sum, nil
+}
+
+// fillOom keeps the max OOM scores of the pack elements. (The OOM killer chooses one process, summing their scores is meaningless)
+func (p *packStat) fillOom() {
+	if p.oomTs == stamp {
+		return
+	}
+	var score uint64
+	var adj int64 = -1000
+	for _, s := range p.elems {
+		v, _ := s.OOMScore()
+		score = maxUint64(score, v)
+		a, _ := s.OOMScoreAdj()
+		adj = maxInt64(adj, a)
+	}
+	p.oomScore = score
+	p.oomAdj = adj
+	p.oomTs = stamp
+}
+
+func (p *packStat) OOMScore() (uint64, error) {
+	p.fillOom()
+	return p.oomScore, nil
+}
+
+func (p *packStat) OOMScoreAdj() (int64, error) {
+	p.fillOom()
+	return p.oomAdj, nil
+}
+
 func (p *packStat) ThreadNumber() (uint64, error) {
 	if p.threadNbTs == stamp {
 		return p.threadNb, nil
@@ -276,6 +687,162 @@ func (p *packStat) FDNumber() (uint64, error) {
 	return sum, nil
 }
 
+func (p *packStat) FDType(k fdKind) (uint64, error) {
+	if p.fdTypeTs[k] == stamp {
+		return p.fdType[k], nil
+	}
+	var sum uint64
+	for _, s := range p.elems {
+		v, _ := s.FDType(k)
+		sum += v
+	}
+	p.fdType[k] = sum
+	p.fdTypeTs[k] = stamp
+	return sum, nil
+}
+
+// fillDeleted counts the union of the deleted files opened by the pack elements. A file inherited by several processes (eg: the log of a master process and its workers) is counted once.
+func (p *packStat) fillDeleted() {
+	if p.deletedTs == stamp {
+		return
+	}
+	all := map[[2]uint64]uint64{}
+	for _, s := range p.elems {
+		if s.status == DEAD || s.IsThread() {
+			continue
+		}
+		s.fillFdTypes()
+		for id, size := range s.deleted {
+			all[id] = size
+		}
+	}
+	var sum uint64
+	for _, size := range all {
+		sum += size
+	}
+	p.deletedNb = uint64(len(all))
+	p.deletedSz = sum
+	p.deletedTs = stamp
+}
+
+func (p *packStat) DeletedFiles() (uint64, error) {
+	p.fillDeleted()
+	return p.deletedNb, nil
+}
+
+func (p *packStat) DeletedBytes() (uint64, error) {
+	p.fillDeleted()
+	return p.deletedSz, nil
+}
+
+// TCPState is the sum of the connections of the pack elements.
+func (p *packStat) TCPState(k tcpState) (uint64, error) {
+	if p.tcpStateTs[k] == stamp {
+		return p.tcpState[k], nil
+	}
+	var sum uint64
+	for _, s := range p.elems {
+		v, _ := s.TCPState(k)
+		sum += v
+	}
+	p.tcpState[k] = sum
+	p.tcpStateTs[k] = stamp
+	return sum, nil
+}
+
+// NetNSTimeWait is the sum of the TIME_WAIT connections of the distinct network namespaces of the pack elements.
+func (p *packStat) NetNSTimeWait() (uint64, error) {
+	if p.nsTWaitTs == stamp {
+		return p.nsTWait, nil
+	}
+	var sum uint64
+	seen := map[uint64]bool{}
+	for _, s := range p.elems {
+		if s.status == DEAD || s.IsThread() {
+			continue // Reports no sockets.
+		}
+		ns := s.netNS()
+		if ns == 0 || seen[ns] {
+			continue
+		}
+		seen[ns] = true
+		v, _ := s.NetNSTimeWait()
+		sum += v
+	}
+	p.nsTWait = sum
+	p.nsTWaitTs = stamp
+	return sum, nil
+}
+
+// ListenPorts is the union of the ports the pack elements are listening on.
+func (p *packStat) ListenPorts() ([]listenPort, error) {
+	if p.listenTs == stamp {
+		return p.listen, nil
+	}
+	lps := p.listen[:0]
+	for _, s := range p.elems {
+		v, _ := s.ListenPorts()
+		for _, lp := range v {
+			lps = addListenPort(lps, lp)
+		}
+	}
+	p.listen = lps
+	p.listenTs = stamp
+	return lps, nil
+}
+
+// fillLimits keeps the tightest limits and the highest limit usage percents of the pack elements. (A limit applies to each process, so the sum is meaningless)
+func (p *packStat) fillLimits() {
+	if p.limitsTs == stamp {
+		return
+	}
+	var fdLimit, nprocLimit uint64
+	var fdPct, nprocPct float32
+	for _, s := range p.elems {
+		v, _ := s.FDLimit()
+		if v != 0 && (fdLimit == 0 || v < fdLimit) {
+			fdLimit = v
+		}
+		v, _ = s.NprocLimit()
+		if v != 0 && (nprocLimit == 0 || v < nprocLimit) {
+			nprocLimit = v
+		}
+		pc, _ := s.FDPercent()
+		if pc > fdPct {
+			fdPct = pc
+		}
+		pc, _ = s.NprocPercent()
+		if pc > nprocPct {
+			nprocPct = pc
+		}
+	}
+	p.fdLimit = fdLimit
+	p.nprocLimit = nprocLimit
+	p.fdPct = fdPct
+	p.nprocPct = nprocPct
+	p.limitsTs = stamp
+}
+
+func (p *packStat) FDLimit() (uint64, error) {
+	p.fillLimits()
+	return p.fdLimit, nil
+}
+
+func (p *packStat) FDPercent() (float32, error) {
+	p.fillLimits()
+	return p.fdPct, nil
+}
+
+func (p *packStat) NprocLimit() (uint64, error) {
+	p.fillLimits()
+	return p.nprocLimit, nil
+}
+
+func (p *packStat) NprocPercent() (float32, error) {
+	p.fillLimits()
+	return p.nprocPct, nil
+}
+
 func (p *packStat) ChildrenPIDs(depth int) []tPid {
 	mall := map[tPid]interface{}{}
 	// reccursively get all children as a slice of gopsutils processes
@@ -390,11 +957,11 @@ func (p *packStat) packBy(by string) []*packStat {
 				mby[v] = packStat
 			}
 		}
-	default: // This is probably a variable name used to store synthetic data.
+	default: // This is probably a variable name used to store synthetic data (or a tag name like processor, state, ...).
 		mby := map[string]*packStat{}
 		for _, ps := range pss {
-			v, exist := ps.vars[by]
-			if !exist || v == "" {
+			v, _ := tagNameToValue(ps, by)
+			if v == "" {
 				v = "(NA)" // Process where the variable does not exist or is empty are packed together with a value of '(NA)'
 			}
 			if packStat, known := mby[v]; known {
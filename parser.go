@@ -489,6 +489,45 @@ func (p *Parser) parseArgInt(pa *int64) error {
 	return p.parseArgSep()
 }
 
+// Duration units (in seconds) accepted after a number by parseArgDuration.
+var durationUnits = map[string]int64{"s": 1, "m": 60, "h": 3600, "d": 86400, "w": 7 * 86400}
+
+// parseArgDuration consumes a positive duration in seconds with an optional unit (eg: 90, 30m, 2h, 1d).
+func (p *Parser) parseArgDuration(pa *int64) error {
+	a, err := p.parseInt()
+	if err != nil {
+		return err
+	}
+	// The unit is scanned as an identifier immediately following the number.
+	tok, lit := p.scan()
+	if tok == tTIdentifier {
+		u, known := durationUnits[strings.ToLower(lit)]
+		if !known {
+			return p.syntaxError(fmt.Sprintf("found %q, expecting a duration unit (s, m, h, d or w)", lit))
+		}
+		a *= u
+	} else {
+		p.unscan()
+	}
+	if a < 0 {
+		return p.syntaxError(fmt.Sprintf("found %d, expecting a positive duration", a))
+	}
+	*pa = a
+	return p.parseArgSep()
+}
+
+// parseArgCompare consumes a comparison operator (<, <=, >, >=, = or ==).
+func (p *Parser) parseArgCompare(pa *string) error {
+	tok, lit := p.scanIgnoreWhitespace()
+	if tok == tTEqual {
+		lit = "=="
+	} else if tok != tTCompare {
+		return p.syntaxError(fmt.Sprintf("found %q, expecting a comparison operator (<, <=, >, >=, ==)", lit))
+	}
+	*pa = lit
+	return p.parseArgSep()
+}
+
 // Consume a , but keep the ) in the scanner, other tokens are syntax errors
 func (p *Parser) parseArgSep() error {
 	tok, lit := p.scanIgnoreWhitespace()
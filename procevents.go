@@ -7,6 +7,7 @@ import "C"
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/shirou/gopsutil/cpu"
 	"github.com/shirou/gopsutil/host"
@@ -18,6 +19,7 @@ var CpuNb uint             // Number of CPUs(cores) on this server. Set during i
 var BootTimeNs uint64      // Time of system boot (ns since epoch)
 var JiffiesPerS float32    // Number of available Jiffies each second for all the cores aggregated (a jiffie is the Linux CPU usage accounting uint.)
 var JiffiesPerNs float32   // see JiffiesPerS but by nano seconds.
+var SmapsRollup bool       // Does the kernel provide the (cheaper) /proc/[pid]/smaps_rollup files?
 
 func init() {
 	// Replace default values with values found on this server. (Should be the same but better safe than sorry)
@@ -29,6 +31,8 @@ func init() {
 	BootTimeNs = bootTime * 1e9
 	JiffiesPerS = float32(CpuNb * ClockTicks)
 	JiffiesPerNs = JiffiesPerS / 1e9
+	_, err := os.Stat("/proc/self/smaps_rollup")
+	SmapsRollup = err == nil
 	trace("jps=%f cpunb=%d", JiffiesPerS, CpuNb)
 }
 
@@ -56,8 +60,12 @@ func goProcEventExec(pid C.int, ts C.ulong) {
 		// This should not happen. (the handling of fork events is defered so the exec event should be the first for a given PID)
 		apsMutex.Unlock()
 		trace("Exec already known process? pid=%d old_cmd=%s", pid, ps.cmd)
-		ps.cmdLine = ""   // reset the cmdline (changed by exec)
-		ps.initFromStat() // reset cmd and other stat related fields.
+		ps.cmdLine = ""       // reset the cmdline (changed by exec)
+		ps.exe = ""           // reset the exe (changed by exec)
+		ps.path = ""          // reset the path (derived from exe)
+		ps.linksRead = false  // read the exe and root links again
+		ps.cgroupRead = false // read the cgroup again (and the unit, container and pod derived from it)
+		ps.initFromStat()     // reset cmd and other stat related fields.
 		trace("Exec pid=%d new_cmd=%s", pid, ps.cmd)
 	}
 	// Clean any corresponding (defered) fork event.+++
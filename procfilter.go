@@ -30,9 +30,12 @@ type ProcFilter struct {
 	Netlink            bool    // Try to use Netlink to get more accurate metrics on short-lived processes?
 	Wakeup_interval    int64   // in ms. How often do we wake up to update some stats (only for some young processes, not all processes)
 	Update_age_ratio   float64 // last_update/age ratio to trigger a new update.
+	Memory_mode        string  // What the rss field measures: approx, rss, pss or uss.
+	Numa_maps          bool    // Parse /proc/[pid]/numa_maps to get the memory per NUMA node (costly).
 	Debug              int64   // Debug mask.
 	parser             *Parser
 	parseOK            bool    // Script parsed OK?
+	memMode            memMode // Parsed value of Memory_mode.
 	netlinkOk          bool    // Using netlink?
 	needOneScan        bool    // If the netlink gets a transiant error, we ask for a rescan of the /proc dir.
 	prevSampleStart    uint64  // Time in unix nanos
@@ -42,7 +45,7 @@ type ProcFilter struct {
 }
 
 func NewProcFilter() *ProcFilter {
-	p := &ProcFilter{Measurement_prefix: "pf.", Netlink: true, Wakeup_interval: 100, Update_age_ratio: 0.5, Debug: 0}
+	p := &ProcFilter{Measurement_prefix: "pf.", Netlink: true, Wakeup_interval: 100, Update_age_ratio: 0.5, Memory_mode: "approx", Debug: 0}
 	curProcFilter = p
 	return p
 }
@@ -60,6 +63,15 @@ var sampleConfig = `
   # wakeup_interval = 100 # in ms
   ## Update age ratio indicates to the sampling goroutine when to update metrics for a process depending on its age. Young processes data get extra updates to collect relevant metrics before they vanish.
   # update_age_ratio = 0.5 # 0 => update all processes every time we wakeup (not recommended), 1.0 => update if the last update done is older than the age of the process. 
+  ## What the rss field measures:
+  ##  approx: the PSS approximated with a low CPU cost (from the raw RSS and a PSS/RSS ratio refreshed once in a while).
+  ##  rss: the raw RSS (shared memory pages are accounted in full for every process).
+  ##  pss: the exact PSS (shared memory pages are divided between the processes sharing them). Costly if the kernel has no smaps_rollup.
+  ##  uss: the USS (only the private memory pages).
+  # memory_mode = "approx"
+  ## Parse /proc/[pid]/numa_maps to get the memory used on each NUMA node (mem_node0, mem_node1, ... fields and numa_imbalance criteria).
+  ## This file lists all the mappings of a process, reading it is costly.
+  # numa_maps = false
   ## Debug flag (among other things, will output the script with line numbers).
   # debug = 0 
   ## Describe what you want to measure by writting a script.
@@ -97,8 +109,31 @@ func init() {
 	})
 }
 
+// Memory accounting modes for the rss field.
+type memMode uint8
+
+const (
+	memModeApprox memMode = iota
+	memModeRSS
+	memModePSS
+	memModeUSS
+)
+
 func (p *ProcFilter) init() {
 	so := "value of script= in configuration file"
+	switch strings.ToLower(p.Memory_mode) {
+	case "approx", "":
+		p.memMode = memModeApprox
+	case "rss":
+		p.memMode = memModeRSS
+	case "pss":
+		p.memMode = memModePSS
+	case "uss":
+		p.memMode = memModeUSS
+	default:
+		logErr(fmt.Sprintf("Unknown memory_mode '%s' (expecting approx, rss, pss or uss), using approx.", p.Memory_mode))
+		p.memMode = memModeApprox
+	}
 	if p.Script_file != "" {
 		if p.Script != "" {
 			logErr(fmt.Sprintf("E! You cannot have non empty script and script_file at the same time"))
@@ -171,7 +171,7 @@ func BenchmarkFastReadLine(b *testing.B) {
 				continue
 			}
 			for {
-				l := fastReadLine2()
+				l := fastReadLine()
 				if l == nil {
 					break
 				}
@@ -192,7 +192,7 @@ func TestFastReadLine(*testing.T) {
 		return
 	}
 	for {
-		l := fastReadLine2()
+		l := fastReadLine()
 		if l == nil {
 			break
 		}
@@ -254,3 +254,508 @@ func TestGather(t *testing.T) {
 		}
 	}
 }
+
+func TestParseNice(t *testing.T) {
+	tests := []struct {
+		script string
+		ok     bool
+	}{
+		{"m = field(pid) <- nice(<,0)", true},
+		{"m = field(pid) <- nice(>=,10)", true},
+		{"m = field(pid) <- nice(=,0)", true},
+		{"m = field(pid) <- nice(0,0)", false},
+	}
+	for _, tt := range tests {
+		err := NewParser(strings.NewReader(tt.script)).Parse()
+		if (err == nil) != tt.ok {
+			t.Errorf("Parse(%q) = %v; want ok=%v", tt.script, err, tt.ok)
+		}
+	}
+	p := NewParser(strings.NewReader("m = field(pid) <- nice(=,0)"))
+	if err := p.Parse(); err == nil {
+		if f, ok := p.measurements[0].f.(*niceFilter); !ok || f.op != "==" {
+			t.Errorf("nice(=,0) = %+v; want the == operator", p.measurements[0].f)
+		}
+	}
+}
+
+func TestParseAge(t *testing.T) {
+	tests := []struct {
+		script string
+		ok     bool
+	}{
+		{"m = field(pid) <- older(1d)", true},
+		{"m = field(pid) <- younger(90)", true},
+		{"m = field(pid) <- older(30m)", true},
+		{"m = field(pid) <- older(2w)", true},
+		{"m = field(pid) <- older(-1h)", false},
+		{"m = field(pid) <- younger(1y)", false},
+		{"m = field(pid) <- exceed(age,1d)", true},
+		{"m = field(pid) <- exceed(age,-1d)", false},
+	}
+	for _, tt := range tests {
+		err := NewParser(strings.NewReader(tt.script)).Parse()
+		if (err == nil) != tt.ok {
+			t.Errorf("Parse(%q) = %v; want ok=%v", tt.script, err, tt.ok)
+		}
+	}
+	p := NewParser(strings.NewReader("m = field(pid) <- older(1d)"))
+	if err := p.Parse(); err == nil {
+		if f, ok := p.measurements[0].f.(*ageFilter); !ok || f.age != 86400 || f.younger {
+			t.Errorf("older(1d) = %+v; want an age of 86400 s", p.measurements[0].f)
+		}
+	}
+}
+
+func TestParseVarNames(t *testing.T) {
+	tests := []struct {
+		script string
+		ok     bool
+	}{
+		{"_ <- setvar('x', level, all)", true},
+		{"_ <- setvar('x', unit, all)", true},
+		{"_ <- revar(exe, '(.*)', '$1', state, all)", true},
+		{"_ <- setvar('x', cmd, all)", false},
+		{"_ <- revar(exe, '(.*)', '$1', k8s_pod, all)", false},
+	}
+	for _, tt := range tests {
+		err := NewParser(strings.NewReader(tt.script)).Parse()
+		if (err == nil) != tt.ok {
+			t.Errorf("Parse(%q) = %v; want ok=%v", tt.script, err, tt.ok)
+		}
+	}
+	s := &procStat{vars: map[string]string{"unit": "mine", "k8s_pod": "mine"}}
+	if v, _ := tagNameToValue(s, "unit"); v != "mine" {
+		t.Errorf("tag(unit) = %q; want the user variable", v)
+	}
+	if v := userVar(s, "k8s_pod"); v != "" {
+		t.Errorf("userVar(k8s_pod) = %q; want the reserved name ignored", v)
+	}
+}
+
+func TestFastParseHex(t *testing.T) {
+	tests := []struct {
+		s     string
+		i     int
+		res   uint64
+		index int
+	}{
+		{"0CEA", 0, 0x0CEA, 4},
+		{"0100007F:0CEA", 0, 0x0100007F, 8},
+		{"0100007F:0CEA", 9, 0x0CEA, 13},
+		{"ff ", 0, 255, 2},
+		{"", 0, 0, 0},
+	}
+	for _, tt := range tests {
+		res, index := fastParseHex([]byte(tt.s), tt.i)
+		if res != tt.res || index != tt.index {
+			t.Errorf("fastParseHex(%q, %d) = %d, %d; want %d, %d", tt.s, tt.i, res, index, tt.res, tt.index)
+		}
+	}
+}
+
+func TestParseSockLine(t *testing.T) {
+	ephemeralLow, ephemeralHigh, ephemeralRead = 32768, 60999, true
+	tests := []struct {
+		l   string
+		udp bool
+		si  sockInfo
+		ino uint64
+	}{
+		// tcp6 listening on [::]:22
+		{"   0: 00000000000000000000000000000000:0016 00000000000000000000000000000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 23456 1 0000000000000000 100 0 0 10 0",
+			false, sockInfo{state: 0x0A, port: 22, listen: true}, 23456},
+		// tcp6 established on [::ffff:127.0.0.1]:8080
+		{"   1: 0000000000000000FFFF00000100007F:1F90 0000000000000000FFFF00000100007F:D2A4 01 00000000:00000000 00:00000000 00000000  1000        0 34567 1 0000000000000000 20 4 30 10 -1",
+			false, sockInfo{state: 0x01, port: 8080}, 34567},
+		// tcp in TIME_WAIT (not owned by a process anymore)
+		{"   2: 0100007F:1F90 0100007F:D2A6 06 00000000:00000000 03:00001770 00000000     0        0 0 3 0000000000000000",
+			false, sockInfo{state: 0x06, port: 8080}, 0},
+		// udp bound to 0.0.0.0:514
+		{"  123: 00000000:0202 00000000:0000 07 00000000:00000000 00:00000000 00000000   102        0 14567 2 0000000000000000 0",
+			true, sockInfo{state: 0x07, port: 514, udp: true, listen: true}, 14567},
+		// unconnected udp client on an ephemeral port
+		{"  456: 00000000:CF12 00000000:0000 07 00000000:00000000 00:00000000 00000000  1000        0 15678 2 0000000000000000 0",
+			true, sockInfo{state: 0x07, port: 0xCF12, udp: true}, 15678},
+		// connected udp socket to 127.0.0.1:53
+		{"  789: 0100007F:A1B2 0100007F:0035 01 00000000:00000000 00:00000000 00000000  1000        0 16789 2 0000000000000000 0",
+			true, sockInfo{state: 0x01, port: 0xA1B2, udp: true}, 16789},
+	}
+	for _, tt := range tests {
+		si, ino := parseSockLine([]byte(tt.l), tt.udp)
+		if si != tt.si || ino != tt.ino {
+			t.Errorf("parseSockLine(%q) = %+v, %d; want %+v, %d", tt.l, si, ino, tt.si, tt.ino)
+		}
+	}
+}
+
+func TestCgroupRank(t *testing.T) {
+	tests := []struct {
+		hierarchy, controllers string
+		rank                   int
+	}{
+		{"0", "", 4},
+		{"1", "name=systemd", 3},
+		{"4", "cpu,cpuacct", 2},
+		{"7", "memory", 1},
+	}
+	for _, tt := range tests {
+		if rank := cgroupRank([]byte(tt.hierarchy), []byte(tt.controllers)); rank != tt.rank {
+			t.Errorf("cgroupRank(%q, %q) = %d; want %d", tt.hierarchy, tt.controllers, rank, tt.rank)
+		}
+	}
+}
+
+const testContainerID = "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+func TestSystemdUnit(t *testing.T) {
+	tests := []struct {
+		cgroup, unit, slice string
+	}{
+		{"/system.slice/nginx.service", "nginx.service", "system.slice"},
+		{"/user.slice/user-1000.slice/session-3.scope", "session-3.scope", "user-1000.slice"},
+		{"/user.slice/user-1000.slice/user@1000.service/app.slice/x.service", "user@1000.service", "user-1000.slice"},
+		{"/init.scope", "init.scope", "-.slice"},
+		{"/system.slice/docker-" + testContainerID + ".scope", "docker-" + testContainerID + ".scope", "system.slice"},
+		{"/docker/" + testContainerID, "", ""},
+		{"/", "", ""},
+	}
+	for _, tt := range tests {
+		unit, slice := systemdUnit(tt.cgroup)
+		if unit != tt.unit || slice != tt.slice {
+			t.Errorf("systemdUnit(%q) = %q, %q; want %q, %q", tt.cgroup, unit, slice, tt.unit, tt.slice)
+		}
+	}
+}
+
+func TestContainerFromCgroup(t *testing.T) {
+	tests := []struct {
+		cgroup, id, runtime string
+	}{
+		{"/system.slice/docker-" + testContainerID + ".scope", testContainerID, "docker"},
+		{"/docker/" + testContainerID, testContainerID, "docker"},
+		{"/kubepods.slice/kubepods-besteffort.slice/kubepods-besteffort-pod0f3c9b2e_1b2a_4c3d_9e8f_0123456789ab.slice/cri-containerd-" + testContainerID + ".scope", testContainerID, "containerd"},
+		{"/kubepods.slice/kubepods-pod0f3c9b2e_1b2a_4c3d_9e8f_0123456789ab.slice/crio-" + testContainerID + ".scope", testContainerID, "cri-o"},
+		{"/kubepods.slice/kubepods-pod0f3c9b2e_1b2a_4c3d_9e8f_0123456789ab.slice/crio-conmon-" + testContainerID + ".scope", "", ""},
+		{"/machine.slice/libpod-" + testContainerID + ".scope/container", testContainerID, "podman"},
+		{"/kubepods/burstable/pod0f3c9b2e-1b2a-4c3d-9e8f-0123456789ab/" + testContainerID, testContainerID, ""},
+		{"/lxc/web", "web", "lxc"},
+		{"/lxc.payload.web", "web", "lxc"},
+		{"/system.slice/nginx.service", "", ""},
+		{"/", "", ""},
+	}
+	for _, tt := range tests {
+		id, runtime := containerFromCgroup(tt.cgroup)
+		if id != tt.id || runtime != tt.runtime {
+			t.Errorf("containerFromCgroup(%q) = %q, %q; want %q, %q", tt.cgroup, id, runtime, tt.id, tt.runtime)
+		}
+	}
+}
+
+func TestK8sPodFromCgroup(t *testing.T) {
+	const uid = "0f3c9b2e-1b2a-4c3d-9e8f-0123456789ab"
+	tests := []struct {
+		cgroup, uid, qos string
+	}{
+		// cgroupfs driver
+		{"/kubepods/burstable/pod" + uid + "/" + testContainerID, uid, "burstable"},
+		{"/kubepods/besteffort/pod" + uid + "/" + testContainerID, uid, "besteffort"},
+		{"/kubepods/pod" + uid + "/" + testContainerID, uid, "guaranteed"},
+		// systemd driver
+		{"/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod0f3c9b2e_1b2a_4c3d_9e8f_0123456789ab.slice/cri-containerd-" + testContainerID + ".scope", uid, "burstable"},
+		{"/kubepods.slice/kubepods-pod0f3c9b2e_1b2a_4c3d_9e8f_0123456789ab.slice/crio-" + testContainerID + ".scope", uid, "guaranteed"},
+		{"/kubelet.slice/kubelet-kubepods.slice/kubelet-kubepods-besteffort.slice/kubelet-kubepods-besteffort-pod0f3c9b2e_1b2a_4c3d_9e8f_0123456789ab.slice/cri-containerd-" + testContainerID + ".scope", uid, "besteffort"},
+		// not a pod
+		{"/system.slice/docker-" + testContainerID + ".scope", "", ""},
+		{"/user.slice/user-1000.slice/user@1000.service/app.slice/x.service", "", ""},
+	}
+	for _, tt := range tests {
+		uid, qos := k8sPodFromCgroup(tt.cgroup)
+		if uid != tt.uid || qos != tt.qos {
+			t.Errorf("k8sPodFromCgroup(%q) = %q, %q; want %q, %q", tt.cgroup, uid, qos, tt.uid, tt.qos)
+		}
+	}
+}
+
+func TestPackCommon(t *testing.T) {
+	tests := []struct {
+		vals []string
+		res  string
+	}{
+		{[]string{"x"}, "x"},
+		{[]string{"x", "x"}, "x"},
+		{[]string{"x", "y"}, ""},
+		{[]string{"", "x"}, ""},
+		{[]string{"x", ""}, ""},
+		{[]string{"", ""}, ""},
+		{nil, ""},
+	}
+	for _, tt := range tests {
+		var elems []*procStat
+		for _, v := range tt.vals {
+			elems = append(elems, &procStat{cmd: v})
+		}
+		res := NewPackStat(elems).common(func(s *procStat) (string, error) { return s.cmd, nil })
+		if res != tt.res {
+			t.Errorf("common(%q) = %q; want %q", tt.vals, res, tt.res)
+		}
+	}
+}
+
+func TestPackState(t *testing.T) {
+	tests := []struct {
+		states string
+		res    string
+	}{
+		{"SS", "S"},
+		{"SR", ""},
+		{"\x00S", ""},
+		{"S\x00", ""},
+	}
+	for _, tt := range tests {
+		var elems []*procStat
+		for i := 0; i < len(tt.states); i++ {
+			// statTs is set so that State does not read /proc.
+			elems = append(elems, &procStat{statTs: stamp, state: tt.states[i]})
+		}
+		res, _ := NewPackStat(elems).State()
+		if res != tt.res {
+			t.Errorf("State(%q) = %q; want %q", tt.states, res, tt.res)
+		}
+	}
+}
+
+func TestPackNS(t *testing.T) {
+	tests := []struct {
+		nss []uint64
+		res uint64
+	}{
+		{[]uint64{4026531840, 4026531840}, 4026531840},
+		{[]uint64{4026531840, 4026532100}, 0},
+		{[]uint64{0, 4026531840}, 0},
+		{[]uint64{4026531840, 0}, 0},
+	}
+	for _, tt := range tests {
+		var elems []*procStat
+		for _, ns := range tt.nss {
+			s := &procStat{nsRead: true} // nsRead is set so that NS does not read /proc.
+			s.ns[nsNet] = ns
+			elems = append(elems, s)
+		}
+		res, _ := NewPackStat(elems).NS(nsNet)
+		if res != tt.res {
+			t.Errorf("NS(%v) = %d; want %d", tt.nss, res, tt.res)
+		}
+	}
+}
+
+func TestAddTaskWaits(t *testing.T) {
+	ps := &procStat{}
+	steps := []struct {
+		waits  map[string]uint64
+		waitNs uint64
+	}{
+		{map[string]uint64{"100": 1000, "101": 500}, 1500},
+		{map[string]uint64{"100": 1200, "101": 900}, 2100},
+		// Thread 101 exited: its wait time is kept.
+		{map[string]uint64{"100": 1300}, 2200},
+		// New thread 102.
+		{map[string]uint64{"100": 1300, "102": 50}, 2250},
+		// TID 100 reused by a new thread.
+		{map[string]uint64{"100": 10, "102": 60}, 2270},
+	}
+	for i, st := range steps {
+		ps.addTaskWaits(st.waits)
+		if ps.waitNs != st.waitNs {
+			t.Errorf("step %d: waitNs = %d; want %d", i, ps.waitNs, st.waitNs)
+		}
+	}
+}
+
+// writeTestFile writes a sample /proc file in a temporary directory and returns its name.
+func writeTestFile(t *testing.T, content string) string {
+	fn := t.TempDir() + "/sample"
+	if err := ioutil.WriteFile(fn, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return fn
+}
+
+func TestUpdateFromNumaMaps(t *testing.T) {
+	tests := []struct {
+		lines string
+		bytes []uint64 // memory per node
+	}{
+		{"7f2c4c021000 default file=/usr/lib/libc.so.6 mapped=5 N0=3 N1=2 kernelpagesize_kB=4\n",
+			[]uint64{3 << 12, 2 << 12}},
+		// Several mappings, a node value at the end of the line, a mapping without pages.
+		{"55d0a1e2b000 default heap anon=10 dirty=10 N0=10 kernelpagesize_kB=4\n" +
+			"7ffd2c5e3000 default stack anon=3 dirty=3 N1=3\n" +
+			"7ffd2c5fc000 default\n",
+			[]uint64{10 << 12, 3 << 12}},
+		// Huge pages, only on node 2.
+		{"7f8000000000 default file=/anon_hugepage\\040(deleted) huge dirty=2 N2=2 kernelpagesize_kB=2048\n",
+			[]uint64{0, 0, 2 << 21}},
+		// N1 keys inside a value are ignored.
+		{"7f2c4c021000 default file=/opt/N1=9 mapped=1 N0=1 kernelpagesize_kB=4\n",
+			[]uint64{1 << 12}},
+	}
+	for _, tt := range tests {
+		stamp++ // A new sample, so that the file is read.
+		ps := &procStat{pfnNuma: writeTestFile(t, tt.lines)}
+		ps.updateFromNumaMaps()
+		if len(ps.numa) != len(tt.bytes) {
+			t.Errorf("numa_maps %q: %d nodes; want %d", tt.lines, len(ps.numa), len(tt.bytes))
+			continue
+		}
+		for n, pages := range ps.numa {
+			if pages*PageSize != tt.bytes[n] {
+				t.Errorf("numa_maps %q: node %d = %d bytes; want %d", tt.lines, n, pages*PageSize, tt.bytes[n])
+			}
+		}
+	}
+}
+
+func TestUpdateFromLimits(t *testing.T) {
+	const header = "Limit                     Soft Limit           Hard Limit           Units     \n"
+	tests := []struct {
+		limits              string
+		fdLimit, nprocLimit uint64
+	}{
+		{header +
+			"Max cpu time              unlimited            unlimited            seconds   \n" +
+			"Max file size             unlimited            unlimited            bytes     \n" +
+			"Max data size             unlimited            unlimited            bytes     \n" +
+			"Max stack size            8388608              unlimited            bytes     \n" +
+			"Max core file size        0                    unlimited            bytes     \n" +
+			"Max resident set          unlimited            unlimited            bytes     \n" +
+			"Max processes             63372                63372                processes \n" +
+			"Max open files            1024                 524288               files     \n" +
+			"Max locked memory         8388608              8388608              bytes     \n" +
+			"Max address space         unlimited            unlimited            bytes     \n" +
+			"Max file locks            unlimited            unlimited            locks     \n" +
+			"Max pending signals       63372                63372                signals   \n" +
+			"Max msgqueue size         819200               819200               bytes     \n" +
+			"Max nice priority         0                    0                    \n" +
+			"Max realtime priority     0                    0                    \n" +
+			"Max realtime timeout      unlimited            unlimited            us        \n",
+			1024, 63372},
+		{header +
+			"Max processes             unlimited            unlimited            processes \n" +
+			"Max open files            unlimited            unlimited            files     \n",
+			0, 0},
+	}
+	for _, tt := range tests {
+		stamp++ // A new sample, so that the file is read.
+		ps := &procStat{pfnLimits: writeTestFile(t, tt.limits)}
+		if err := ps.updateFromLimits(); err != nil {
+			t.Fatal(err)
+		}
+		if ps.fdLimit != tt.fdLimit || ps.nprocLimit != tt.nprocLimit {
+			t.Errorf("limits: fd=%d nproc=%d; want fd=%d nproc=%d", ps.fdLimit, ps.nprocLimit, tt.fdLimit, tt.nprocLimit)
+		}
+	}
+}
+
+func TestStatusMemKey(t *testing.T) {
+	tests := []struct {
+		line string
+		k    memKind
+		val  uint64 // bytes
+	}{
+		{"RssAnon:\t    1024 kB", memRssAnon, 1024 << 10},
+		{"RssFile:\t     512 kB", memRssFile, 512 << 10},
+		{"RssShmem:\t       8 kB", memRssShmem, 8 << 10},
+		{"VmHWM:\t    2048 kB", memRssPeak, 2048 << 10},
+		{"VmPeak:\t  123456 kB", memVszPeak, 123456 << 10},
+		{"VmLck:\t       4 kB", memLocked, 4 << 10},
+		{"VmPTE:\t      60 kB", memPTE, 60 << 10},
+		{"HugetlbPages:\t    4096 kB", memHugetlb, 4096 << 10},
+	}
+	for _, tt := range tests {
+		k, l := statusMemKey([]byte(tt.line), 0)
+		if l == 0 || k != tt.k {
+			t.Errorf("statusMemKey(%q) = %d, %d; want %d", tt.line, k, l, tt.k)
+		}
+		// The same line within a status file.
+		stamp++ // A new sample, so that the file is read.
+		ps := &procStat{pfnStatus: writeTestFile(t, "Name:\tbash\nVmSize:\t  8000 kB\n"+tt.line+"\nnonvoluntary_ctxt_switches:\t13\n")}
+		ps.updateFromStatus()
+		if ps.mem[tt.k] != tt.val {
+			t.Errorf("status %q: mem[%d] = %d; want %d", tt.line, tt.k, ps.mem[tt.k], tt.val)
+		}
+	}
+	// Lines that are not memKind keys.
+	for _, line := range []string{"VmSize:\t  8000 kB", "VmRSS:\t  3000 kB", "VmSwap:\t 0 kB", "Name:\tbash"} {
+		if _, l := statusMemKey([]byte(line), 0); l != 0 {
+			t.Errorf("statusMemKey(%q) found a key", line)
+		}
+	}
+}
+
+func TestUpdateFromIO(t *testing.T) {
+	ps := &procStat{pfnIo: writeTestFile(t, "rchar: 323934931\nwchar: 323929600\nsyscr: 632687\nsyscw: 632675\nread_bytes: 809781143552\nwrite_bytes: 4366930132992\ncancelled_write_bytes: 4096\n")}
+	ps.updateFromIO()
+	want := [ioKindNb]uint64{
+		ioRchar:     323934931,
+		ioWchar:     323929600,
+		ioSyscr:     632687,
+		ioSyscw:     632675,
+		ioRead:      809781143552,
+		ioWrite:     4366930132992,
+		ioCancelled: 4096,
+	}
+	if ps.ioc != want {
+		t.Errorf("io = %v; want %v", ps.ioc, want)
+	}
+	if ps.io != 809781143552+4366930132992 {
+		t.Errorf("io = %d; want the sum of read_bytes and write_bytes", ps.io)
+	}
+}
+
+func TestReadNetDev(t *testing.T) {
+	dev := "Inter-|   Receive                                                |  Transmit\n" +
+		" face |bytes    packets errs drop fifo frame compressed multicast|bytes    packets errs drop fifo colls carrier compressed\n" +
+		"    lo: 54318052    8930    0    0    0     0          0         0 54318052    8930    0    0    0     0       0          0\n" +
+		"  eth0:     1882      27    0    0    0     0          0         0     2106      24    0    0    0     0       0          0\n" +
+		"eth1:1000 10 0 0 0 0 0 0 2000 20 0 0 0 0 0 0\n"
+	var nt netTraffic
+	if !nt.readNetDev(writeTestFile(t, dev)) {
+		t.Fatal("readNetDev failed")
+	}
+	want := [netKindNb]uint64{
+		netRxBytes:   1882 + 1000,
+		netRxPackets: 27 + 10,
+		netTxBytes:   2106 + 2000,
+		netTxPackets: 24 + 20,
+	}
+	if nt.cnt != want {
+		t.Errorf("net/dev = %v; want %v (without lo)", nt.cnt, want)
+	}
+}
+
+func TestFdTypeOf(t *testing.T) {
+	tests := []struct {
+		target string
+		k      fdKind
+	}{
+		{"socket:[21586]", fdSocket},
+		{"pipe:[12345]", fdPipe},
+		{"anon_inode:[eventpoll]", fdAnon},
+		{"anon_inode:inotify", fdAnon},
+		{"/dev/null", fdDevice},
+		{"/dev/pts/0", fdDevice},
+		{"/dev/shm/sem.x", fdFile},
+		{"/memfd:wayland-shm (deleted)", fdOther},
+		{"/var/log/app.log", fdFile},
+		{"/var/log/app.log (deleted)", fdFile},
+		// A named FIFO opened by its path is a file (the targets are not stat-ed).
+		{"/run/app/control.fifo", fdFile},
+		{"net:[4026531840]", fdOther},
+	}
+	for _, tt := range tests {
+		if k := fdTypeOf(tt.target); k != tt.k {
+			t.Errorf("fdTypeOf(%q) = %d; want %d", tt.target, k, tt.k)
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -27,61 +28,144 @@ var strIdUnknown = "[unknown]"
 var apsMutex = sync.Mutex{}
 var allProcStats = map[tPid]*procStat{}
 
+// Threads enumerated by the threads() filters (indexed by TID). Threads not enumerated during a sample are removed.
+var allThreadStats = map[tPid]*procStat{}
+
 // Keep all fork event (a trick to avoid a double access to /proc/[pid]/ for every fork/exec)
 var forksMutex = sync.Mutex{}
 var forks = map[tPid]uint64{}
 
 /* Stats for a process */
 type procStat struct {
-	pid         tPid   // this process PID
-	ppid        tPid   // parent PID
-	tgid        tPid   // thread group ID (!= PID if in a thread)
-	startTime   uint64 // start time as Unix nanos.
-	deathTime   uint64 // exit/death time as Unix nanos.
-	status      Status // The process last known status (new,young..dead).
-	prevUpdTime uint64 // time at last sample.
-	prevCpu     uint64 // total cpu used in jiffies at last sample
-	updTime     uint64 // last update time as Unix nanos.
-	stamp       tStamp // last stamp during which this process found
-	pfnStat     string // "/proc/[pid]/stat"
-	pfnStatus   string // "/proc/[pid]/status"
-	pfnSmaps    string // "/proc/[pid]/smaps"
-	pfnIo       string // "/proc/[pid]/io"
-	cpu         uint64 // total cpu used jiffies at updtime.         // user time
-	cmd         string
-	exe         string
-	cmdLine     string // \0 are replaced by ´ ´ for optimization (I know we loose some information for args with embedded spaces)
-	path        string
-	cpuTs       tStamp  // last upadte of cpu metric. TODO merge with staTs?
-	cpupc       float32 // cpu usage percent
-	statTs      tStamp  // Last update based on content of status file
-	rss         uint64
-	vsz         uint64
-	threadNb    uint32
-	statusTs    tStamp // Last update based on content of status file
-	uid         int32
-	gid         int32
-	swap        uint64
-	user        string
-	group       string
-	smapsTs     tStamp
-	pss         uint64  // like RSS but with a better accounting of shared memory.
-	rprss       float32 // ratio PSS/RSS
-	fdNbTs      tStamp
-	fdNb        uint32
-	ioTs        tStamp
-	prevIo      uint64
-	prevIoTime  time.Time
-	io          uint64
-	ioTime      time.Time
-	vars        map[string]string // Synthetized variables (see revar filter)
+	pid              tPid   // this process PID
+	ppid             tPid   // parent PID
+	tgid             tPid   // thread group ID (!= PID if in a thread)
+	startTime        uint64 // start time as Unix nanos.
+	deathTime        uint64 // exit/death time as Unix nanos.
+	status           Status // The process last known status (new,young..dead).
+	state            byte   // The process state as found in /proc/[pid]/stat (R,S,D,Z,T,...).
+	priority         int32  // Kernel priority (negative for realtime processes).
+	nice             int32  // Nice level (-20..19).
+	rtPriority       uint32 // Realtime priority (1..99, 0 for non realtime processes).
+	policy           uint32 // Scheduling policy (SCHED_OTHER, SCHED_FIFO, ...).
+	processor        int32  // CPU the process last ran on.
+	cpusAllowed      string // CPUs the process is allowed to run on (eg: 0-3,8).
+	prevUpdTime      uint64 // time at last sample.
+	prevCpu          uint64 // total cpu used in jiffies at last sample
+	updTime          uint64 // last update time as Unix nanos.
+	stamp            tStamp // last stamp during which this process found
+	pfnStat          string // "/proc/[pid]/stat"
+	pfnStatus        string // "/proc/[pid]/status"
+	pfnSmaps         string // "/proc/[pid]/smaps"
+	pfnIo            string // "/proc/[pid]/io"
+	pfnSched         string // "/proc/[tgid]/task/[tid]/schedstat" (threads only)
+	pfnOom           string // "/proc/[pid]/oom_score"
+	pfnOomAdj        string // "/proc/[pid]/oom_score_adj"
+	pfnLimits        string // "/proc/[pid]/limits"
+	pfnNuma          string // "/proc/[pid]/numa_maps"
+	cpu              uint64 // total cpu used jiffies at updtime.         // user time
+	utime            uint64 // cpu used in user mode (jiffies) at updtime.
+	stime            uint64 // cpu used in kernel mode (jiffies) at updtime.
+	prevUtime        uint64 // cpu used in user mode (jiffies) at last sample.
+	prevStime        uint64 // cpu used in kernel mode (jiffies) at last sample.
+	blkio            uint64 // time spent waiting for block IO (jiffies) at updtime.
+	prevBlkio        uint64 // time spent waiting for block IO (jiffies) at last sample.
+	cmd              string
+	exe              string
+	cwd              string // Current working directory (readlink of /proc/[pid]/cwd).
+	root             string // Root directory (readlink of /proc/[pid]/root), not "/" for chrooted or containerized processes.
+	linksRead        bool   // exe and root symlinks already read.
+	cwdTs            tStamp // last read of the cwd symlink.
+	cgroup           string // cgroup path (eg: /system.slice/nginx.service).
+	cgroupRead       bool   // /proc/[pid]/cgroup already read.
+	unit             string // systemd unit found in the cgroup path (eg: nginx.service).
+	slice            string // systemd slice found in the cgroup path (eg: system.slice).
+	containerID      string // container ID found in the cgroup path.
+	containerRuntime string // container runtime found in the cgroup path ("" if unknown).
+	podUID           string // kubernetes pod UID found in the cgroup path.
+	podQoS           string // kubernetes pod QoS class found in the cgroup path.
+	cmdLine          string // \0 are replaced by ´ ´ for optimization (I know we loose some information for args with embedded spaces)
+	path             string
+	cpuTs            tStamp  // last upadte of cpu metric. TODO merge with staTs?
+	cpupc            float32 // cpu usage percent
+	cpuUserPc        float32 // cpu usage percent in user mode
+	cpuSysPc         float32 // cpu usage percent in kernel mode
+	ioWaitPc         float32 // percent of time spent waiting for block IO
+	statTs           tStamp  // Last update based on content of status file
+	rss              uint64
+	vsz              uint64
+	threadNb         uint32
+	statusTs         tStamp // Last update based on content of status file
+	uid              int32
+	gid              int32
+	swap             uint64
+	mem              [memKindNb]uint64 // Detailed memory values from the status and smaps files (in bytes).
+	user             string
+	group            string
+	smapsTs          tStamp
+	rprss            float32 // ratio PSS/RSS
+	fdNbTs           tStamp
+	fdNb             uint32
+	fdTypeTs         tStamp
+	fdTypes          [fdKindNb]uint32     // Number of open files by type.
+	deleted          map[[2]uint64]uint64 // Open files that were deleted (indexed by device and inode) and their disk space still in use.
+	sockInodes       []uint64             // Inodes of the open sockets.
+	ns               [nsKindNb]uint64     // Inodes of the namespaces.
+	nsRead           bool                 // ns already read?
+	nsPid            tPid                 // PID in the PID namespace of the process.
+	sockTs           tStamp               // last update of the sockets metrics.
+	tcpStates        [tcpStateNb]uint32   // Number of TCP connections by state.
+	nsTimeWait       uint32               // Number of TIME_WAIT connections of the network namespace.
+	listenPorts      []listenPort         // Listening ports.
+	ioTs             tStamp
+	prevIo           uint64
+	prevIoTime       time.Time
+	io               uint64
+	ioTime           time.Time
+	ioc              [ioKindNb]uint64  // Counters from /proc/[pid]/io.
+	prevIoc          [ioKindNb]uint64  // Counters from /proc/[pid]/io at last sample.
+	minFlt           uint64            // minor page faults (no disk access needed) counter.
+	majFlt           uint64            // major page faults (page read from disk) counter.
+	fltTs            tStamp            // last update of the page fault rates.
+	prevMinFlt       uint64            // minor page faults counter at last sample.
+	prevMajFlt       uint64            // major page faults counter at last sample.
+	prevFltTime      uint64            // time of the page fault counters at last sample as Unix nanos.
+	minFltRate       float32           // minor page faults per second during the last interval.
+	majFltRate       float32           // major page faults per second during the last interval.
+	statusTime       uint64            // last read of the status file as Unix nanos.
+	volCtxSw         uint64            // voluntary context switches counter (the process waited for a resource).
+	nvolCtxSw        uint64            // non voluntary context switches counter (the process was preempted).
+	ctxSwTs          tStamp            // last update of the context switch rates.
+	prevVolCtx       uint64            // voluntary context switches counter at last sample.
+	prevNvolCtx      uint64            // non voluntary context switches counter at last sample.
+	prevCtxTime      uint64            // time of the context switches counters at last sample as Unix nanos.
+	volCtxRate       float32           // voluntary context switches per second during the last interval.
+	nvolCtxRate      float32           // non voluntary context switches per second during the last interval.
+	waitNs           uint64            // time spent waiting on a run queue (runnable but not running) in ns.
+	schedTime        uint64            // last read of the schedstat file as Unix nanos.
+	schedTs          tStamp            // last update of the run queue wait percent.
+	prevWaitNs       uint64            // time spent waiting on a run queue at last sample.
+	prevSchedT       uint64            // time of the run queue wait counter at last sample as Unix nanos.
+	taskWaits        map[string]uint64 // run queue wait of each thread (by TID) at last read.
+	cpuWaitPc        float32           // percent of the last interval spent waiting on a run queue.
+	oomTs            tStamp            // last update of the OOM killer scores.
+	oomScore         uint64            // badness score used by the OOM killer to choose its victim.
+	oomScoreAdj      int64             // adjustment of the OOM score (-1000 means never kill).
+	limitsTs         tStamp            // last update of the resource limits.
+	numaTs           tStamp            // last update of the memory per NUMA node.
+	numa             []uint64          // memory pages per NUMA node (in PageSize pages).
+	fdLimit          uint64            // soft limit for the number of open files (RLIMIT_NOFILE), 0 if unlimited.
+	nprocLimit       uint64            // soft limit for the number of processes/threads of the user (RLIMIT_NPROC), 0 if unlimited.
+	thread           bool              // Created by a threads() filter from /proc/[tgid]/task/[tid]/ (even for the main thread).
+	threadName       string            // Thread name (comm) for a thread, cmd is the process command name.
+	vars             map[string]string // Synthetized variables (see revar filter)
 }
 
 func (p *procStat) tracef(d int, format string, a ...interface{}) {
 	if p.cmd != "cpustress" {
 		return
 	}
-	tracec(2, d, format, a)
+	tracec(2, d, format, a...)
 }
 
 func (p *procStat) trace(d int) {
@@ -114,6 +198,9 @@ func CpuCount() uint {
 
 func (p *procStat) IsThread() bool {
 	//trace("pid=%d statusTs=%d", p.pid, p.statusTs)
+	if p.thread {
+		return true
+	}
 	if p.statusTs == 0 { // Read only once, tgid does not change.
 		// Never read /proc/pid/status => we must init the tgid.
 		p.updateFromStatus()
@@ -126,6 +213,27 @@ func (p *procStat) PID() tPid {
 	return p.pid
 }
 
+// OutputPID is the PID output in the pid tag/field (the PID of the process for a thread enumerated by a threads() filter).
+func (p *procStat) OutputPID() tPid {
+	if p.thread {
+		return p.tgid
+	}
+	return p.pid
+}
+
+// TID is the thread ID for a thread enumerated by a threads() filter (0 for a process).
+func (p *procStat) TID() tPid {
+	if p.thread {
+		return p.pid
+	}
+	return 0
+}
+
+// ThreadName is the name (comm) of a thread enumerated by a threads() filter ("" for a process).
+func (p *procStat) ThreadName() (string, error) {
+	return p.threadName, nil
+}
+
 func (p *procStat) ProcessNumber() uint64 {
 	if p.IsThread() {
 		return 0
@@ -137,22 +245,58 @@ func (p *procStat) ProcessNumber() uint64 {
 // No specific code to handle the hyperthreading case where a core is not a real core. So when we compute the total available juffies we assume 100% thread availability.
 // This can be tricky because we have to take care of a lot of special cases. (process is dead without CPU counter uupdate, very short lived processes, ...)
 func (p *procStat) CPU() (float32, error) {
+	p.fillCPU()
+	return p.cpupc, nil
+}
+
+// CPUUser is the CPU percent used in user mode since the last telegraf sample (see CPU()).
+func (p *procStat) CPUUser() (float32, error) {
+	p.fillCPU()
+	return p.cpuUserPc, nil
+}
+
+// CPUSystem is the CPU percent used in kernel mode (syscalls, page faults, ...) since the last telegraf sample (see CPU()).
+func (p *procStat) CPUSystem() (float32, error) {
+	p.fillCPU()
+	return p.cpuSysPc, nil
+}
+
+// IOWait is the percent of the last interval the process spent waiting for block IO (requires the kernel delay accounting).
+func (p *procStat) IOWait() (float32, error) {
+	p.fillCPU()
+	return p.ioWaitPc, nil
+}
+
+// fillCPU computes the total, user and system CPU percents (and the block IO wait percent) for the current sample.
+func (p *procStat) fillCPU() {
 	if p.cpuTs == stamp {
-		return p.cpupc, nil
+		return
 	}
 	p.updateFromStat() // Refresh process CPU counter.
 	// cpu is counted as jiffies that are CPU quantum of time allocated to the process.
-	var jiffies uint64
+	var jiffies, ujiffies, sjiffies, bjiffies uint64
 	if p.cpu != 0 {
 		if p.prevUpdTime != 0 {
 			// We have a complete sample. (usual case for long lived processes_
 			if p.cpu > p.prevCpu { // protect against an unsigned int isue if there is an inversion in counters.
 				jiffies = p.cpu - p.prevCpu
 			}
+			if p.utime > p.prevUtime {
+				ujiffies = p.utime - p.prevUtime
+			}
+			if p.stime > p.prevStime {
+				sjiffies = p.stime - p.prevStime
+			}
+			if p.blkio > p.prevBlkio {
+				bjiffies = p.blkio - p.prevBlkio
+			}
 		} else if p.startTime > curProcFilter.prevSampleStart {
 			// First sample for this process.
 			// But the process started during this interval soe know that these jiffies belong to this sample.
 			jiffies = p.cpu
+			ujiffies = p.utime
+			sjiffies = p.stime
+			bjiffies = p.blkio
 		} /*else {
 			// We don't know enough to assign these jiffies.
 			// TODO we could try to guess a prorata using startime?
@@ -161,6 +305,9 @@ func (p *procStat) CPU() (float32, error) {
 		// Clear these jiffies that are now accounted for.
 		p.prevCpu = p.cpu
 		p.cpu = 0
+		p.prevUtime = p.utime
+		p.prevStime = p.stime
+		p.prevBlkio = p.blkio
 	} /*else {
 	// No ungathered CPU counters (jiffies).
 	if p.status != DEAD {
@@ -189,11 +336,16 @@ func (p *procStat) CPU() (float32, error) {
 		}
 		// On some [flush] thread we get an enormous cpu value.
 		cpupc = 0
+		ujiffies = 0
+		sjiffies = 0
 	}
+	p.cpuUserPc = float32(100*ujiffies) / (curProcFilter.sampleDurationS * JiffiesPerS)
+	p.cpuSysPc = float32(100*sjiffies) / (curProcFilter.sampleDurationS * JiffiesPerS)
+	// blkio ticks are a wall clock delay of the task (not CPU time spread over the cores), so 100% is the whole interval.
+	p.ioWaitPc = float32(100*bjiffies) / (curProcFilter.sampleDurationS * float32(ClockTicks))
 	p.prevUpdTime = p.updTime
 	p.cpupc = cpupc
 	p.cpuTs = stamp
-	return cpupc, nil
 }
 
 func (p *procStat) fillIO() {
@@ -202,11 +354,13 @@ func (p *procStat) fillIO() {
 	}
 	p.ioTs = stamp
 	ioLast := p.io // Store the previous sample IO counter value.
+	iocLast := p.ioc
 	p.updateFromIO()
 	if p.status == DEAD {
 		return
 	}
 	p.prevIo = ioLast
+	p.prevIoc = iocLast
 	p.prevIoTime = p.ioTime
 	p.ioTime = time.Now()
 	return
@@ -236,6 +390,125 @@ func (p *procStat) IObps() (uint64, error) {
 	return iobps, nil
 }
 
+// IOStat is the increase of an IO counter during the last sample interval.
+func (p *procStat) IOStat(k ioKind) (uint64, error) {
+	p.fillIO()
+	if p.prevIoTime.IsZero() || p.ioc[k] < p.prevIoc[k] {
+		// First tick, we need 2 ticks to compute a delta.
+		return 0, nil
+	}
+	return p.ioc[k] - p.prevIoc[k], nil
+}
+
+// IOStatRate is the per second rate of an IO counter during the last sample interval.
+func (p *procStat) IOStatRate(k ioKind) (uint64, error) {
+	d, _ := p.IOStat(k)
+	if d == 0 {
+		return 0, nil
+	}
+	dt := p.ioTime.Sub(p.prevIoTime).Seconds()
+	return uint64(float64(d) / dt), nil
+}
+
+// counterRate computes the per second rate of a monotonic counter between two samples. (0 if the counter went backward or no time elapsed.)
+func counterRate(cur, prev, curTime, prevTime uint64) float32 {
+	if cur <= prev || curTime <= prevTime {
+		return 0
+	}
+	return float32(cur-prev) * 1e9 / float32(curTime-prevTime)
+}
+
+// fillFlt computes the page fault rates for the current sample.
+func (p *procStat) fillFlt() {
+	if p.fltTs == stamp {
+		return
+	}
+	p.fltTs = stamp
+	if p.statTs != stamp {
+		p.updateFromStat()
+	}
+	if p.prevFltTime == 0 && p.startTime > curProcFilter.prevSampleStart {
+		// First sample for this process but it started during this interval so all its faults belong to this sample.
+		p.prevFltTime = p.startTime
+	}
+	if p.prevFltTime != 0 {
+		p.minFltRate = counterRate(p.minFlt, p.prevMinFlt, p.updTime, p.prevFltTime)
+		p.majFltRate = counterRate(p.majFlt, p.prevMajFlt, p.updTime, p.prevFltTime)
+	}
+	p.prevMinFlt = p.minFlt
+	p.prevMajFlt = p.majFlt
+	p.prevFltTime = p.updTime
+}
+
+// MinFlt is the number of minor page faults per second during the last interval.
+func (p *procStat) MinFlt() (float32, error) {
+	p.fillFlt()
+	return p.minFltRate, nil
+}
+
+// MajFlt is the number of major page faults (that required a disk access) per second during the last interval.
+func (p *procStat) MajFlt() (float32, error) {
+	p.fillFlt()
+	return p.majFltRate, nil
+}
+
+// fillCtxSw computes the context switch rates for the current sample.
+func (p *procStat) fillCtxSw() {
+	if p.ctxSwTs == stamp {
+		return
+	}
+	p.ctxSwTs = stamp
+	p.updateFromStatus()
+	if p.prevCtxTime == 0 && p.startTime > curProcFilter.prevSampleStart {
+		// First sample for this process but it started during this interval so all its switches belong to this sample.
+		p.prevCtxTime = p.startTime
+	}
+	if p.prevCtxTime != 0 {
+		p.volCtxRate = counterRate(p.volCtxSw, p.prevVolCtx, p.statusTime, p.prevCtxTime)
+		p.nvolCtxRate = counterRate(p.nvolCtxSw, p.prevNvolCtx, p.statusTime, p.prevCtxTime)
+	}
+	p.prevVolCtx = p.volCtxSw
+	p.prevNvolCtx = p.nvolCtxSw
+	p.prevCtxTime = p.statusTime
+}
+
+// VolCtxSw is the number of voluntary context switches per second during the last interval.
+func (p *procStat) VolCtxSw() (float32, error) {
+	p.fillCtxSw()
+	return p.volCtxRate, nil
+}
+
+// NvolCtxSw is the number of non voluntary context switches per second during the last interval.
+func (p *procStat) NvolCtxSw() (float32, error) {
+	p.fillCtxSw()
+	return p.nvolCtxRate, nil
+}
+
+// fillSched computes the run queue wait percent for the current sample.
+func (p *procStat) fillSched() {
+	if p.schedTs == stamp {
+		return
+	}
+	p.schedTs = stamp
+	p.updateFromSchedstat()
+	if p.prevSchedT == 0 && p.startTime > curProcFilter.prevSampleStart {
+		// First sample for this process but it started during this interval so all its wait time belongs to this sample.
+		p.prevSchedT = p.startTime
+	}
+	if p.prevSchedT != 0 {
+		p.cpuWaitPc = counterRate(p.waitNs, p.prevWaitNs, p.schedTime, p.prevSchedT) / 1e7 // ns per s to percent.
+	}
+	p.prevWaitNs = p.waitNs
+	p.prevSchedT = p.schedTime
+}
+
+// CPUWait is the percent of the last interval the threads of the process spent runnable but waiting for a CPU (100% means one thread waited the whole interval).
+// A process using little CPU but waiting a lot is starved by the scheduler.
+func (p *procStat) CPUWait() (float32, error) {
+	p.fillSched()
+	return p.cpuWaitPc, nil
+}
+
 func (p *procStat) GID() (int32, error) {
 	if p.statusTs != 0 {
 		return p.gid, nil
@@ -272,6 +545,133 @@ func (p *procStat) User() (string, error) {
 	return u, nil
 }
 
+// State is the last known state of the process (R running, S sleeping, D uninterruptible sleep (usually IO), Z zombie, T stopped, ...)
+func (p *procStat) State() (string, error) {
+	if p.statTs != stamp {
+		p.updateFromStat()
+	}
+	if p.state == 0 {
+		return "", nil
+	}
+	return string(p.state), nil
+}
+
+// StateNumber returns 1 if the process state is one of the states (eg: "Tt").
+func (p *procStat) StateNumber(states string) uint64 {
+	if p.IsThread() {
+		return 0
+	}
+	st, _ := p.State()
+	if st != "" && strings.Contains(states, st) {
+		return 1
+	}
+	return 0
+}
+
+// Priority is the kernel priority of the process (negative for realtime processes).
+func (p *procStat) Priority() (int64, error) {
+	if p.statTs != stamp {
+		p.updateFromStat()
+	}
+	return int64(p.priority), nil
+}
+
+// Nice is the nice level of the process (-20 for the most favorable scheduling, 19 for the least).
+func (p *procStat) Nice() (int64, error) {
+	if p.statTs != stamp {
+		p.updateFromStat()
+	}
+	return int64(p.nice), nil
+}
+
+// RTPriority is the realtime priority of the process (1..99, 0 for non realtime processes).
+func (p *procStat) RTPriority() (uint64, error) {
+	if p.statTs != stamp {
+		p.updateFromStat()
+	}
+	return uint64(p.rtPriority), nil
+}
+
+// StartTime is the process start time as Unix nanos (0 if unknown).
+func (p *procStat) StartTime() (uint64, error) {
+	return p.startTime, nil
+}
+
+// Age is the number of seconds since the process start (at the beginning of the current sample).
+func (p *procStat) Age() (uint64, error) {
+	if p.startTime == 0 || p.startTime > curProcFilter.sampleStart {
+		return 0, nil
+	}
+	return (curProcFilter.sampleStart - p.startTime) / 1e9, nil
+}
+
+// YoungestAge is the age of the process (see packStat).
+func (p *procStat) YoungestAge() (uint64, error) {
+	return p.Age()
+}
+
+// MemNode is the number of memory pages (of PageSize) of the process on a NUMA node. (Only if the numa_maps option is set)
+func (p *procStat) MemNode(node int) (uint64, error) {
+	if !curProcFilter.Numa_maps || p.IsThread() {
+		return 0, nil
+	}
+	p.updateFromNumaMaps()
+	if node >= len(p.numa) {
+		return 0, nil
+	}
+	return p.numa[node], nil
+}
+
+// NumaImbalance is the percent of the process memory that is not on its main NUMA node.
+func (p *procStat) NumaImbalance() (float32, error) {
+	if !curProcFilter.Numa_maps || p.IsThread() {
+		return 0, nil
+	}
+	p.updateFromNumaMaps()
+	return numaImbalance(p.numa), nil
+}
+
+// numaImbalance computes the percent of pages that are not on the node holding the most pages.
+func numaImbalance(pages []uint64) float32 {
+	var total, max uint64
+	for _, v := range pages {
+		total += v
+		max = maxUint64(max, v)
+	}
+	if total == 0 {
+		return 0
+	}
+	return float32(100*(total-max)) / float32(total)
+}
+
+// Processor is the number of the CPU the process last ran on.
+func (p *procStat) Processor() (int64, error) {
+	if p.statTs != stamp {
+		p.updateFromStat()
+	}
+	return int64(p.processor), nil
+}
+
+// CpusAllowed is the list of CPUs the process is allowed to run on (eg: 0-3,8) as set by taskset or cpusets.
+func (p *procStat) CpusAllowed() (string, error) {
+	p.updateFromStatus()
+	return p.cpusAllowed, nil
+}
+
+// Names of the scheduling policies (see man sched_setscheduler), indexed by policy number.
+var schedPolicyNames = []string{"other", "fifo", "rr", "batch", "iso", "idle", "deadline"}
+
+// SchedPolicy is the name of the scheduling policy of the process (other, fifo, rr, batch, idle, deadline).
+func (p *procStat) SchedPolicy() (string, error) {
+	if p.statTs != stamp {
+		p.updateFromStat()
+	}
+	if int(p.policy) >= len(schedPolicyNames) {
+		return strconv.Itoa(int(p.policy)), nil
+	}
+	return schedPolicyNames[p.policy], nil
+}
+
 // The short executable name (without path)
 func (p *procStat) Cmd() (string, error) {
 	// cmd is always initialized during ps creation.
@@ -283,7 +683,8 @@ func (p *procStat) Exe() (string, error) {
 	if p.exe != "" {
 		return p.exe, nil
 	}
-	if p.cmdLine == "" { // the chdmline file has never been read.
+	p.updateFromLinks()
+	if p.exe == "" && p.cmdLine == "" { // the exe link is not readable and the chdmline file has never been read.
 		p.updateFromCmdline() // This will fill the exe field.
 	}
 	if p.exe == "" {
@@ -300,6 +701,18 @@ func (p *procStat) CmdLine() (string, error) {
 	return p.cmdLine, nil
 }
 
+// Cwd is the current working directory of the process.
+func (p *procStat) Cwd() (string, error) {
+	p.updateFromCwd()
+	return p.cwd, nil
+}
+
+// Root is the root directory of the process ("/" unless chrooted or in a container with its own mount namespace).
+func (p *procStat) Root() (string, error) {
+	p.updateFromLinks()
+	return p.root, nil
+}
+
 func (p *procStat) Path() (string, error) {
 	if p.path != "" {
 		return p.path, nil
@@ -317,16 +730,22 @@ func (p *procStat) Path() (string, error) {
 // TODO Debug only.
 var rssc, pssc int64
 
-// RSS is in fact a best effort that will try to take into account the number of processes that are using the same shared memory maps (ie: the PSS value). The heuristic that is used to avoid the costly computation of the PSS is based on the difference between RSS and initial PSS. If the difference is big then the PSS is computed and returned in place of the simple but far from exact RSS.
+// RSS depends on the memory_mode option. The raw RSS, the exact PSS or USS, or by default (approx) a best effort that will try to take into account the number of processes that are using the same shared memory maps (ie: the PSS value). The heuristic that is used to avoid the costly computation of the PSS is based on the difference between RSS and initial PSS. If the difference is big then the PSS is computed and returned in place of the simple but far from exact RSS.
 func (p *procStat) RSS() (uint64, error) {
 	if p.IsThread() {
 		return 0, nil
 	}
+	switch curProcFilter.memMode {
+	case memModePSS:
+		return p.Mem(memPss)
+	case memModeUSS:
+		return p.Mem(memUss)
+	}
 
 	// Refresh RSS from .../stats file.
 	p.updateFromStat()
 	// Refreshing PSS from the (big) .../smaps file is costly so try to avoid it.
-	if p.rss < 8*1024*1024 { // RSS < 8M we ignore the RSS vs PSS difference.
+	if curProcFilter.memMode == memModeRSS || p.rss < 8*1024*1024 { // RSS < 8M we ignore the RSS vs PSS difference.
 		return p.rss, nil
 	}
 	if p.smapsTs == 0 || stamp == 2 || p.rprss < 0.1 {
@@ -347,10 +766,10 @@ func (p *procStat) PSS() (uint64, error) {
 		return 0, nil
 	}
 	if p.smapsTs == stamp {
-		return p.pss, nil
+		return p.mem[memPss], nil
 	}
 	p.updateFromSmaps() // TODO debug
-	return p.pss, nil
+	return p.mem[memPss], nil
 }
 
 func (p *procStat) VSZ() (uint64, error) {
@@ -375,6 +794,37 @@ func (p *procStat) Swap() (uint64, error) {
 	return p.swap, nil
 }
 
+// Mem returns one of the detailed memory values found in /proc/[pid]/status (rss_anon, rss_file, rss_shmem, ...) or /proc/[pid]/smaps (pss, uss, swap_pss).
+func (p *procStat) Mem(k memKind) (uint64, error) {
+	if p.IsThread() {
+		return 0, nil
+	}
+	if k < memStatusNb {
+		p.updateFromStatus()
+	} else {
+		p.updateFromSmaps()
+	}
+	return p.mem[k], nil
+}
+
+// OOMScore is the score used by the OOM killer to choose its victim (the biggest score is killed first).
+func (p *procStat) OOMScore() (uint64, error) {
+	if p.IsThread() {
+		return 0, nil
+	}
+	err := p.updateFromOom()
+	return p.oomScore, err
+}
+
+// OOMScoreAdj is the adjustment of the OOM score set for this process (from -1000 to 1000).
+func (p *procStat) OOMScoreAdj() (int64, error) {
+	if p.IsThread() {
+		return 0, nil
+	}
+	err := p.updateFromOom()
+	return p.oomScoreAdj, err
+}
+
 func (p *procStat) ThreadNumber() (uint64, error) {
 	if p.IsThread() {
 		return 1, nil
@@ -398,6 +848,156 @@ func (p *procStat) FDNumber() (uint64, error) {
 	return uint64(p.fdNb), err
 }
 
+// fillFdTypes refreshes the open files types and deleted files once per sample.
+func (p *procStat) fillFdTypes() error {
+	if p.fdTypeTs == stamp {
+		return nil
+	}
+	p.fdTypeTs = stamp
+	return p.updateFromFdTypes()
+}
+
+// FDType is the number of open files of a given type (socket, pipe, ...).
+func (p *procStat) FDType(k fdKind) (uint64, error) {
+	if p.status == DEAD || p.IsThread() {
+		return 0, nil
+	}
+	err := p.fillFdTypes()
+	return uint64(p.fdTypes[k]), err
+}
+
+// DeletedFiles is the number of files opened by the process that were deleted (eg: a rotated log file still in use).
+func (p *procStat) DeletedFiles() (uint64, error) {
+	if p.status == DEAD || p.IsThread() {
+		return 0, nil
+	}
+	err := p.fillFdTypes()
+	return uint64(len(p.deleted)), err
+}
+
+// DeletedBytes is the disk space used by the deleted files still opened by the process. This disk space is freed only when the files are closed.
+func (p *procStat) DeletedBytes() (uint64, error) {
+	if p.status == DEAD || p.IsThread() {
+		return 0, nil
+	}
+	err := p.fillFdTypes()
+	var sum uint64
+	for _, size := range p.deleted {
+		sum += size
+	}
+	return sum, err
+}
+
+// fillSockets finds the open sockets of the process in the socket tables of its network namespace.
+func (p *procStat) fillSockets() {
+	if p.sockTs == stamp {
+		return
+	}
+	p.sockTs = stamp
+	p.tcpStates = [tcpStateNb]uint32{}
+	p.nsTimeWait = 0
+	p.listenPorts = p.listenPorts[:0]
+	if p.status == DEAD || p.IsThread() {
+		return
+	}
+	t := sockTable(p)
+	if t == nil {
+		return
+	}
+	p.fillFdTypes()
+	p.nsTimeWait = t.timeWait
+	for _, ino := range p.sockInodes {
+		si, known := t.socks[ino]
+		if !known {
+			continue // eg: unix or netlink socket.
+		}
+		if !si.udp && 0 < si.state && si.state <= uint8(tcpStateNb) {
+			p.tcpStates[si.state-1]++
+		}
+		if si.listen {
+			p.listenPorts = addListenPort(p.listenPorts, listenPort{si.port, si.udp})
+		}
+	}
+}
+
+// TCPState is the number of TCP connections of the process in a given state. It is always 0 for TIME_WAIT (these connections are not owned by a process anymore, see NetNSTimeWait).
+func (p *procStat) TCPState(k tcpState) (uint64, error) {
+	p.fillSockets()
+	return uint64(p.tcpStates[k]), nil
+}
+
+// NetNSTimeWait is the number of TCP connections in the TIME_WAIT state of the network namespace of the process.
+func (p *procStat) NetNSTimeWait() (uint64, error) {
+	p.fillSockets()
+	return uint64(p.nsTimeWait), nil
+}
+
+// ListenPorts is the sorted list of TCP and UDP ports the process is listening on.
+func (p *procStat) ListenPorts() ([]listenPort, error) {
+	p.fillSockets()
+	return p.listenPorts, nil
+}
+
+// FDLimit is the maximum number of open files for this process (0 if unlimited).
+func (p *procStat) FDLimit() (uint64, error) {
+	if p.IsThread() {
+		return 0, nil
+	}
+	err := p.updateFromLimits()
+	return p.fdLimit, err
+}
+
+// FDPercent is the percent of the open files limit used by this process.
+func (p *procStat) FDPercent() (float32, error) {
+	limit, err := p.FDLimit()
+	if err != nil || limit == 0 {
+		return 0, err
+	}
+	nb, err := p.FDNumber()
+	if err != nil {
+		return 0, err
+	}
+	return float32(100*nb) / float32(limit), nil
+}
+
+// NprocLimit is the maximum number of processes (threads in fact) for the user of this process (0 if unlimited).
+func (p *procStat) NprocLimit() (uint64, error) {
+	if p.IsThread() {
+		return 0, nil
+	}
+	err := p.updateFromLimits()
+	return p.nprocLimit, err
+}
+
+// NprocPercent is the percent of the processes limit used. This limit applies to the total number of threads of the user of this process.
+func (p *procStat) NprocPercent() (float32, error) {
+	limit, err := p.NprocLimit()
+	if err != nil || limit == 0 {
+		return 0, err
+	}
+	uid, _ := p.UID()
+	return float32(100*userThreadNumber(uid)) / float32(limit), nil
+}
+
+// Number of threads by user, computed once per sample.
+var uidThreads = map[int32]uint64{}
+var uidThreadsTs tStamp
+
+// userThreadNumber counts the threads of all the processes of a user (this is the number checked against the RLIMIT_NPROC limit).
+func userThreadNumber(uid int32) uint64 {
+	if uidThreadsTs != stamp {
+		uidThreads = map[int32]uint64{}
+		for _, ps := range allProcStats {
+			if ps.status == DEAD || ps.IsThread() {
+				continue
+			}
+			uidThreads[ps.uid] += uint64(ps.threadNb)
+		}
+		uidThreadsTs = stamp
+	}
+	return uidThreads[uid]
+}
+
 func (p *procStat) ChildrenPIDs(depth int) []tPid {
 	tree := map[tPid]*procStat{}
 	roots := []*procStat{}
@@ -442,6 +1042,8 @@ func (p *procStat) Var(name string) string {
 	val, known := p.vars[name]
 	if known {
 		return val
+	} else if _, k8s := k8sVarNames[name]; k8s {
+		return p.k8sVar(name)
 	} else {
 		return ""
 	}
@@ -548,6 +1150,61 @@ func addNewProcStat(pid tPid, ts uint64, lock bool) bool {
 	return true
 }
 
+// threadStats returns the threads of a process (creating the missing ones). They are stamped with the current sample stamp to keep them alive.
+func threadStats(ps *procStat, threads []*procStat) []*procStat {
+	d, err := os.Open(procFileName(ps.pid, "task"))
+	if err != nil {
+		return threads
+	}
+	fnames, err := d.Readdirnames(-1)
+	d.Close()
+	if err != nil {
+		return threads
+	}
+	for _, fname := range fnames {
+		tid, err := strconv.ParseInt(fname, 10, 32)
+		if err != nil {
+			continue
+		}
+		ts, known := allThreadStats[tPid(tid)]
+		if !known || ts.tgid != ps.pid {
+			ts = newThreadStat(ps, tPid(tid))
+			if ts == nil {
+				continue // Already gone.
+			}
+			allThreadStats[ts.pid] = ts
+		}
+		ts.stamp = stamp
+		threads = append(threads, ts)
+	}
+	return threads
+}
+
+// newThreadStat creates a procStat for a thread. Its metrics are read from /proc/[tgid]/task/[tid]/ (/proc/[tid]/ would give the process metrics for the main thread).
+func newThreadStat(ps *procStat, tid tPid) *procStat {
+	s := procStat{}
+	s.status = ADULT
+	s.pid = tid
+	s.tgid = ps.pid
+	s.thread = true
+	dir := fmt.Sprintf("/proc/%d/task/%d/", ps.pid, tid)
+	s.pfnStat = dir + "stat"
+	s.pfnStatus = dir + "status"
+	s.pfnSched = dir + "schedstat"
+	s.pfnIo = dir + "io" // Per thread IO accounting.
+	s.uid = -1
+	s.gid = -1
+	if !s.initFromStat() {
+		return nil
+	}
+	s.threadName = s.cmd // The stat file of a thread contains the thread name.
+	s.cmd = ps.cmd
+	s.exe = ps.exe
+	s.cmdLine = ps.cmdLine
+	s.path = ps.path
+	return &s
+}
+
 // Inner function carved out of the loop to defer the mutex unlock. Compute time until next invocation to match Fast_interval pace.
 func updateProcstatsHelper(p *ProcFilter) time.Duration {
 	uar := curProcFilter.Update_age_ratio
@@ -635,6 +1292,11 @@ func clearOldProcStats() {
 			dp++
 		}
 	}
+	for tid, ts := range allThreadStats {
+		if ts.status == DEAD || ts.stamp != stamp {
+			delete(allThreadStats, tid) // Dead or not enumerated anymore.
+		}
+	}
 	apsMutex.Unlock()
 	if dp > 0 {
 		trace("removed %d dead processes.", dp)
@@ -27,6 +27,7 @@ const (
 	tTEqual      // =
 	tTComma      // ,
 	tTBang       // !
+	tTCompare    // <, <=, >, >=, ==
 	tTIllegal
 )
 
@@ -83,6 +84,10 @@ func (s *Scanner) scan() (tok tokenType, lit string) {
 	case ',':
 		return tTComma, string(ch)
 	case '=':
+		if ch := s.read(); ch == '=' {
+			return tTCompare, "=="
+		}
+		s.unread()
 		return tTEqual, string(ch)
 	case '!':
 		return tTBang, string(ch)
@@ -90,8 +95,24 @@ func (s *Scanner) scan() (tok tokenType, lit string) {
 		ch := s.read()
 		if ch == '-' {
 			return tTLeftArrow, "<-"
+		} else if ch == '=' {
+			return tTCompare, "<="
+		}
+		s.unread()
+		return tTCompare, "<"
+	case '>':
+		if ch := s.read(); ch == '=' {
+			return tTCompare, ">="
+		}
+		s.unread()
+		return tTCompare, ">"
+	case '-':
+		if ch := s.read(); isDigit(ch) {
+			s.unread()
+			_, lit := s.scanNumber()
+			return tTNumber, "-" + lit
 		}
-		return tTIllegal, string(ch)
+		s.unread()
 	}
 
 	return tTIllegal, string(ch)
@@ -156,8 +177,6 @@ func (s *Scanner) scanString(d rune) (tok tokenType, lit string) {
 			buf.WriteRune(ch)
 		}
 	}
-
-	return tTIllegal, buf.String() // unreachable code
 }
 
 // scanIdent consumes the current rune and all contiguous ident runes ([._a-zA-Z0-9${}]).
@@ -181,7 +200,6 @@ func (s *Scanner) scanIdentifier() (tok tokenType, lit string) {
 
 	// Otherwise return as a regular identifier.
 	id := buf.String()
-	fmt.Sprintf("id:%q", id)
 	return tTIdentifier, id
 }
 
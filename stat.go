@@ -1,8 +1,9 @@
 package procfilter
 
+import "fmt"
+
 //	"fmt"
 
-// TODO add IO stats?
 // TODO add percent for memory?
 
 // TODO convert the maps used during apply() to slices?
@@ -15,6 +16,9 @@ type tStamp uint8 // A stamp used to identify samples.
 /* A real process or a group of (packed) processes will implement this interface to get to the underlying statistics. */
 type stat interface {
 	PID() tPid
+	OutputPID() tPid
+	TID() tPid
+	ThreadName() (string, error)
 	UID() (int32, error)
 	User() (string, error)
 	GID() (int32, error)
@@ -22,14 +26,63 @@ type stat interface {
 	RSS() (uint64, error)
 	VSZ() (uint64, error)
 	Swap() (uint64, error)
+	Mem(memKind) (uint64, error)
+	IOStat(ioKind) (uint64, error)
+	IOStatRate(ioKind) (uint64, error)
+	FDType(fdKind) (uint64, error)
+	DeletedFiles() (uint64, error)
+	DeletedBytes() (uint64, error)
+	TCPState(tcpState) (uint64, error)
+	NetNSTimeWait() (uint64, error)
+	ListenPorts() ([]listenPort, error)
+	NetRate(netKind) (uint64, error)
+	OOMScore() (uint64, error)
+	OOMScoreAdj() (int64, error)
 	CPU() (float32, error)
+	CPUUser() (float32, error)
+	CPUSystem() (float32, error)
+	CPUWait() (float32, error)
+	IOWait() (float32, error)
 	IO() (uint64, error)
 	IObps() (uint64, error)
+	MinFlt() (float32, error)
+	MajFlt() (float32, error)
+	VolCtxSw() (float32, error)
+	NvolCtxSw() (float32, error)
 	ProcessNumber() uint64
+	State() (string, error)
+	StateNumber(string) uint64
+	Priority() (int64, error)
+	Nice() (int64, error)
+	RTPriority() (uint64, error)
+	SchedPolicy() (string, error)
+	Processor() (int64, error)
+	MemNode(int) (uint64, error)
+	NumaImbalance() (float32, error)
+	StartTime() (uint64, error)
+	Age() (uint64, error)
+	YoungestAge() (uint64, error)
+	CpusAllowed() (string, error)
 	ThreadNumber() (uint64, error)
 	FDNumber() (uint64, error)
+	FDLimit() (uint64, error)
+	FDPercent() (float32, error)
+	NprocLimit() (uint64, error)
+	NprocPercent() (float32, error)
 	Path() (string, error)
 	Exe() (string, error)
+	Cwd() (string, error)
+	Root() (string, error)
+	Cgroup() (string, error)
+	Unit() (string, error)
+	Slice() (string, error)
+	ContainerID() (string, error)
+	ContainerName() (string, error)
+	ContainerImage() (string, error)
+	ContainerRuntime() (string, error)
+	NS(k nsKind) (uint64, error)
+	NSPid() tPid
+	Hostname() (string, error)
 	Cmd() (string, error)
 	CmdLine() (string, error)
 	ChildrenPIDs(int) []tPid
@@ -37,6 +90,258 @@ type stat interface {
 	PVars() *(map[string]string) // Pointer to the inner map.
 }
 
+// Detailed memory values found in /proc/[pid]/status.
+type memKind int
+
+const (
+	memRssAnon  memKind = iota // resident anonymous memory (heap, stack, ...)
+	memRssFile                 // resident file mappings (code, mmaped files, ...)
+	memRssShmem                // resident shared memory (SysV/POSIX shm, tmpfs, shared anonymous mappings)
+	memRssPeak                 // peak resident set size (high water mark)
+	memVszPeak                 // peak virtual memory size
+	memLocked                  // locked memory (mlock)
+	memPTE                     // page table entries size
+	memHugetlb                 // hugetlb pages
+	memPss                     // proportional set size (shared pages are divided between the processes sharing them)
+	memUss                     // unique set size (private clean and dirty pages)
+	memSwapPss                 // proportional swap size
+	memKindNb                  // Number of memKind values.
+)
+
+// memKind values below this one are read from /proc/[pid]/status, the others from /proc/[pid]/smaps.
+const memStatusNb = memPss
+
+// Criteria/field names for the memKind values.
+var memKindNames = map[string]memKind{
+	"rss_anon":  memRssAnon,
+	"rss_file":  memRssFile,
+	"rss_shmem": memRssShmem,
+	"rss_peak":  memRssPeak,
+	"vsz_peak":  memVszPeak,
+	"vm_locked": memLocked,
+	"vm_pte":    memPTE,
+	"hugetlb":   memHugetlb,
+	"pss":       memPss,
+	"uss":       memUss,
+	"swap_pss":  memSwapPss,
+}
+
+// Keys of the memKind values in /proc/[pid]/status (in kB). Values read from smaps have no key here.
+var memKindKeys = [memKindNb]string{
+	memRssAnon:  "RssAnon:",
+	memRssFile:  "RssFile:",
+	memRssShmem: "RssShmem:",
+	memRssPeak:  "VmHWM:",
+	memVszPeak:  "VmPeak:",
+	memLocked:   "VmLck:",
+	memPTE:      "VmPTE:",
+	memHugetlb:  "HugetlbPages:",
+}
+
+// Counters found in /proc/[pid]/io.
+type ioKind int
+
+const (
+	ioRead      ioKind = iota // bytes read from the storage layer
+	ioWrite                   // bytes written to the storage layer
+	ioRchar                   // bytes read by read() like syscalls (including page cache hits, pipes, ...)
+	ioWchar                   // bytes written by write() like syscalls
+	ioSyscr                   // number of read() like syscalls
+	ioSyscw                   // number of write() like syscalls
+	ioCancelled               // bytes not written after all (truncated dirty page cache)
+	ioKindNb                  // Number of ioKind values.
+)
+
+// Criteria/field names for the ioKind deltas (over the last sample interval).
+var ioKindNames = map[string]ioKind{
+	"io_read":               ioRead,
+	"io_write":              ioWrite,
+	"rchar":                 ioRchar,
+	"wchar":                 ioWchar,
+	"syscr":                 ioSyscr,
+	"syscw":                 ioSyscw,
+	"cancelled_write_bytes": ioCancelled,
+}
+
+// Criteria/field names for the ioKind rates (per second).
+var ioRateNames = map[string]ioKind{
+	"io_read_bps":         ioRead,
+	"io_write_bps":        ioWrite,
+	"rchar_bps":           ioRchar,
+	"wchar_bps":           ioWchar,
+	"syscr_rate":          ioSyscr,
+	"syscw_rate":          ioSyscw,
+	"cancelled_write_bps": ioCancelled,
+}
+
+// Keys of the ioKind values in /proc/[pid]/io.
+var ioKindKeys = [ioKindNb]string{
+	ioRead:      "read_bytes:",
+	ioWrite:     "write_bytes:",
+	ioRchar:     "rchar:",
+	ioWchar:     "wchar:",
+	ioSyscr:     "syscr:",
+	ioSyscw:     "syscw:",
+	ioCancelled: "cancelled_write_bytes:",
+}
+
+// Types of the files opened by a process (targets of the /proc/[pid]/fd/ links).
+type fdKind int
+
+const (
+	fdFile   fdKind = iota // regular files and directories (and named FIFOs opened by path, the fd targets are not stat-ed)
+	fdSocket               // sockets
+	fdPipe                 // pipes
+	fdAnon                 // anonymous inodes (eventfd, eventpoll, inotify, timerfd, signalfd, ...)
+	fdDevice               // devices (/dev/...)
+	fdOther                // others (namespaces, memfd, ...)
+	fdKindNb               // Number of fdKind values.
+)
+
+// Criteria/field names for the fdKind values.
+var fdKindNames = map[string]fdKind{
+	"fd_file_nb":   fdFile,
+	"fd_socket_nb": fdSocket,
+	"fd_pipe_nb":   fdPipe,
+	"fd_anon_nb":   fdAnon,
+	"fd_device_nb": fdDevice,
+	"fd_other_nb":  fdOther,
+}
+
+// Criteria/field names of the values computed over the sampling interval (percents of the interval and rates per second). They are not known until the 2nd sample.
+var intervalNames = map[string]func(stat) (float32, error){
+	"cpu_user":   stat.CPUUser,
+	"cpu_system": stat.CPUSystem,
+	"cpu_wait":   stat.CPUWait,
+	"io_wait":    stat.IOWait,
+	"min_flt":    stat.MinFlt,
+	"maj_flt":    stat.MajFlt,
+	"vol_ctxsw":  stat.VolCtxSw,
+	"nvol_ctxsw": stat.NvolCtxSw,
+}
+
+// Criteria/field names of the other float values.
+var ratioNames = map[string]func(stat) (float32, error){
+	"fd_pct":         stat.FDPercent,
+	"nproc_pct":      stat.NprocPercent,
+	"numa_imbalance": stat.NumaImbalance,
+}
+
+// Criteria/field names of the other unsigned values.
+var countNames = map[string]func(stat) (uint64, error){
+	"oom_score":        stat.OOMScore,
+	"rt_priority":      stat.RTPriority,
+	"fd_limit":         stat.FDLimit,
+	"nproc_limit":      stat.NprocLimit,
+	"age":              stat.Age,
+	"youngest_age":     stat.YoungestAge,
+	"deleted_files_nb": stat.DeletedFiles,
+	"deleted_bytes":    stat.DeletedBytes,
+	"netns_time_wait":  stat.NetNSTimeWait,
+}
+
+// Criteria/field names of the signed values.
+var signedNames = map[string]func(stat) (int64, error){
+	"oom_score_adj": stat.OOMScoreAdj,
+	"nice":          stat.Nice,
+	"priority":      stat.Priority,
+}
+
+// isKindName checks if name is a memKind, ioKind, fdKind, tcpState or netKind criteria/field name, or one of the scalar criteria/field names above.
+func isKindName(name string) bool {
+	if _, ok := intervalNames[name]; ok {
+		return true
+	}
+	if _, ok := ratioNames[name]; ok {
+		return true
+	}
+	if _, ok := countNames[name]; ok {
+		return true
+	}
+	if _, ok := signedNames[name]; ok {
+		return true
+	}
+	if _, ok := memKindNames[name]; ok {
+		return true
+	}
+	if _, ok := ioKindNames[name]; ok {
+		return true
+	}
+	if _, ok := ioRateNames[name]; ok {
+		return true
+	}
+	if _, ok := fdKindNames[name]; ok {
+		return true
+	}
+	if _, ok := tcpStateNames[name]; ok {
+		return true
+	}
+	_, ok := netRateNames[name]
+	return ok
+}
+
+// kindValue returns the value of a memKind, ioKind, fdKind, tcpState or netKind criteria/field name, or of an unsigned scalar one.
+func kindValue(s stat, name string) (uint64, error) {
+	if get, ok := countNames[name]; ok {
+		return get(s)
+	}
+	if k, ok := memKindNames[name]; ok {
+		return s.Mem(k)
+	}
+	if k, ok := ioKindNames[name]; ok {
+		return s.IOStat(k)
+	}
+	if k, ok := ioRateNames[name]; ok {
+		return s.IOStatRate(k)
+	}
+	if k, ok := fdKindNames[name]; ok {
+		return s.FDType(k)
+	}
+	if k, ok := tcpStateNames[name]; ok {
+		return s.TCPState(k)
+	}
+	if k, ok := netRateNames[name]; ok {
+		return s.NetRate(k)
+	}
+	return 0, fmt.Errorf("unknown criteria %q", name)
+}
+
+// kindFloat returns the value of any isKindName criteria as a float (used to sort and compare).
+func kindFloat(s stat, name string) (float64, error) {
+	if get, ok := intervalNames[name]; ok {
+		v, err := get(s)
+		return float64(v), err
+	}
+	if get, ok := ratioNames[name]; ok {
+		v, err := get(s)
+		return float64(v), err
+	}
+	if get, ok := signedNames[name]; ok {
+		v, err := get(s)
+		return float64(v), err
+	}
+	v, err := kindValue(s, name)
+	return float64(v), err
+}
+
+// kindField returns the value of any isKindName field with its own type (nil if not known yet).
+func kindField(s stat, name string) (interface{}, error) {
+	if get, ok := intervalNames[name]; ok {
+		if stamp == 1 {
+			// Not known until 2nd sample
+			return nil, nil
+		}
+		return get(s)
+	}
+	if get, ok := ratioNames[name]; ok {
+		return get(s)
+	}
+	if get, ok := signedNames[name]; ok {
+		return get(s)
+	}
+	return kindValue(s, name)
+}
+
 type stats struct {
 	stamp    tStamp // Stamp the pid2Stat to know to what sample it refers.
 	pid2Stat map[tPid]stat
@@ -60,6 +365,10 @@ type byThreadNumber statSlice
 type byFDNumber statSlice
 type byIO statSlice
 type byIObps statSlice
+type byKind struct {
+	name string // a kind or scalar criteria name (see isKindName)
+	statSlice
+}
 
 func (s byRSS) Len() int {
 	return len(s)
@@ -196,6 +505,21 @@ func (s byIObps) Less(i, j int) bool {
 	return iv > jv
 }
 
+func (s statSlice) Len() int {
+	return len(s)
+}
+
+func (s statSlice) Swap(i, j int) {
+	s[i], s[j] = s[j], s[i]
+}
+
+func (s byKind) Less(i, j int) bool {
+	// use > (instead of <) to reverse the sort order and get the biggest first
+	iv, _ := kindFloat(s.statSlice[i], s.name)
+	jv, _ := kindFloat(s.statSlice[j], s.name)
+	return iv > jv
+}
+
 func processOldForks() {
 	apsMutex.Lock()
 	forksMutex.Lock()
@@ -14,7 +14,7 @@ import (
 	"strings"
 )
 
-var traceOn = true
+var traceOn = false // Tracing is a debug helper, enabled by tron().
 
 func tron() {
 	traceOn = true
@@ -60,7 +60,6 @@ func traceCallers(from int, nb int) string {
 
 // tracec like trace but with a deeper stack trace.
 func tracec(from int, nb int, format string, a ...interface{}) {
-	return
 	if traceOn == false {
 		return
 	}
@@ -75,7 +74,6 @@ func tracec(from int, nb int, format string, a ...interface{}) {
 
 // trace helper during debug.
 func trace(format string, a ...interface{}) {
-	return
 	if traceOn == false {
 		return
 	}
@@ -218,6 +216,23 @@ func maxUint64(a, b uint64) uint64 {
 	return b
 }
 
+// compareInt64 applies a comparison operator (as parsed by parseArgCompare) to a and b.
+func compareInt64(a int64, op string, b int64) bool {
+	switch op {
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	case "==":
+		return a == b
+	}
+	return false
+}
+
 func logErr(msg string) {
 	log.Printf("E! procfilter: %s", msg)
 }